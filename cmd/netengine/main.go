@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"skynet-net-engine-api/internal/config"
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/pkg/logger"
+)
+
+const usage = `Usage:
+  netengine migrate up [-steps N]     Apply pending migrations (all by default)
+  netengine migrate down [-steps N]   Revert applied migrations (1 by default)
+  netengine migrate status            List migrations and when they were applied
+`
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "migrate" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd := os.Args[2]
+	flags := flag.NewFlagSet("migrate "+cmd, flag.ExitOnError)
+	steps := flags.Int("steps", 0, "number of migrations (0 = all for up, 1 for down)")
+	flags.Parse(os.Args[3:])
+
+	logger.Init()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	database.Configure(cfg.Database)
+	if err := database.Connect(); err != nil {
+		log.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer database.Close()
+
+	switch cmd {
+	case "up":
+		n, err := database.Live.MigrateUp(*steps)
+		if err != nil {
+			log.Fatalf("❌ Migration failed after %d applied: %v", n, err)
+		}
+		fmt.Printf("✅ Applied %d migration(s)\n", n)
+	case "down":
+		n, err := database.Live.MigrateDown(*steps)
+		if err != nil {
+			log.Fatalf("❌ Rollback failed after %d reverted: %v", n, err)
+		}
+		fmt.Printf("✅ Reverted %d migration(s)\n", n)
+	case "status":
+		states, err := database.Live.MigrationStatus()
+		if err != nil {
+			log.Fatalf("❌ Failed to read migrations: %v", err)
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -1,87 +1,82 @@
 package main
 
 import (
-	"database/sql"
+	"flag"
 	"fmt"
 	"log"
-	"os"
 
-	_ "github.com/go-sql-driver/mysql"
+	"skynet-net-engine-api/internal/config"
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/inventory"
+	"skynet-net-engine-api/pkg/logger"
 )
 
+// Imports a router inventory file (see routers.example.yaml) into the routers table.
+// Entries are saved under their file ID. An entry is skipped when its host and port
+// are already stored under another ID, or its ID belongs to a router at another
+// host and port, so an existing router is never overwritten by a different one.
 func main() {
-	// 1. Connection logic (Simplified version of internal/database for the script)
-	dsn := os.Getenv("DB_DSN")
-	if dsn == "" {
-		dsn = "fairusinampratama@tcp(127.0.0.1:3306)/netengine?parseTime=true"
+	logger.Init()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	database.Configure(cfg.Database)
+
+	defaultFile := cfg.Routers.File
+	if defaultFile == "" {
+		defaultFile = "routers.yaml"
 	}
+	file := flag.String("file", defaultFile, "router inventory (.yaml or .json)")
+	flag.Parse()
 
-	db, err := sql.Open("mysql", dsn)
+	routers, err := inventory.Load(*file)
 	if err != nil {
-		log.Fatal("Failed to open DB:", err)
+		log.Fatalf("Failed to load %s: %v", *file, err)
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
+	if err := database.Connect(); err != nil {
 		log.Fatal("Failed to connect to DB:", err)
 	}
+	defer database.Close()
+	if database.AutoMigrate {
+		database.Migrate()
+	}
 
 	fmt.Println("✅ Connected to Database")
 
-	// 2. Data to Seed (From User Image)
-	// Two credential sets based on user feedback:
-	// - Most routers: skynet / sky123!@#
-	// - Routers 1,6,8,11,13,15: skysky / skylineR34!@#
-	
-	routers := []struct {
-		Name string
-		Host string
-		Port int
-		User string
-		Pass string
-	}{
-		{"Randuagung-CCR", "tunnel.ebilling.id", 3724, "skysky", "skylineR34!@#"},
-		{"Skynet Srigading", "tunnel.ebilling.id", 1973, "skysky", "skylineR34!@#"},
-		{"Skynet Arjosari", "tunnel.ebilling.id", 3718, "skynet", "sky123!@#"},
-		{"Skynet Krian", "103.156.128.34", 8777, "skynet", "sky123!@#"},
-		{"Skynet-Rest-Area-Karang-Ploso", "tunnel.ebilling.id", 3625, "skynet", "sky123!@#"},
-		{"Skynet Lawang", "tunnel.ebilling.id", 3499, "skysky", "skylineR34!@#"},
-		{"Skynet Kunci", "tunnel.ebilling.id", 3496, "skynet", "sky123!@#"},
-		{"Skynet Purwosari - Purwodadi", "tunnel.ebilling.id", 16980, "skysky", "skylineR34!@#"},
-		{"Skynet Tutur", "tunnel.ebilling.id", 8200, "skysky", "skylineR34!@#"},
-		{"Skynet Bukit Sentul", "tunnel.ebilling.id", 9529, "skynet", "sky123!@#"},
-		{"Skynet Bantaran", "tunnel.ebilling.id", 14939, "skysky", "skylineR34!@#"},
-		{"Skynet Kasin", "tunnel.ebilling.id", 2734, "skynet", "sky123!@#"},
-		{"Skynet Tasikmadu", "tunnel.ebilling.id", 15515, "skysky", "skylineR34!@#"},
-		{"Skynet Kendit", "tunnel.ebilling.id", 16295, "skynet", "sky123!@#"},
-		{"Skynet Bumiayu", "tunnel2.ebilling.id", 23506, "skysky", "skylineR34!@#"},
+	existing, err := database.Live.GetAllRouters()
+	if err != nil {
+		log.Fatal("Failed to read routers:", err)
+	}
+	idByAddress := make(map[string]int, len(existing))
+	addressByID := make(map[int]string, len(existing))
+	for _, r := range existing {
+		address := fmt.Sprintf("%s:%d", r.Host, r.Port)
+		idByAddress[address] = r.ID
+		addressByID[r.ID] = address
 	}
 
-	fmt.Printf("🌱 Seeding %d routers...\n", len(routers))
+	fmt.Printf("🌱 Seeding %d routers from %s...\n", len(routers), *file)
 
 	for _, r := range routers {
 		// Check duplicates
-		var exists int
-		err = db.QueryRow("SELECT COUNT(*) FROM routers WHERE host = ? AND port = ?", r.Host, r.Port).Scan(&exists)
-		if err != nil {
-			log.Printf("❌ Checking %s: %v\n", r.Name, err)
+		address := fmt.Sprintf("%s:%d", r.Host, r.Port)
+		if id, ok := idByAddress[address]; ok && id != r.ID {
+			fmt.Printf("⚠️  Skipping %s (Already exists as router %d)\n", r.Name, id)
 			continue
 		}
-
-		if exists > 0 {
-			fmt.Printf("⚠️  Skipping %s (Already exists)\n", r.Name)
+		if other, ok := addressByID[r.ID]; ok && other != address {
+			fmt.Printf("⚠️  Skipping %s (ID %d belongs to the router at %s)\n", r.Name, r.ID, other)
 			continue
 		}
 
-		_, err = db.Exec("INSERT INTO routers (name, host, port, username, password) VALUES (?, ?, ?, ?, ?)", 
-			r.Name, r.Host, r.Port, r.User, r.Pass)
-		
-		if err != nil {
+		if err := database.Live.SaveRouter(r); err != nil {
 			log.Printf("❌ Failed to seed %s: %v\n", r.Name, err)
 		} else {
 			fmt.Printf("✅ Seeded: %s\n", r.Name)
 		}
 	}
-	
+
 	fmt.Println("🎉 Seeding Complete!")
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"skynet-net-engine-api/internal/config"
 	"skynet-net-engine-api/internal/database"
 	"skynet-net-engine-api/internal/core"
 	"skynet-net-engine-api/internal/api"
@@ -31,19 +32,38 @@ func main() {
 	logger.Init()
 	logger.Info("Starting NetEngine...", zap.String("version", "1.0.0"))
 
+	// Configuration: CONFIG_FILE and environment, rejected as a whole if anything is invalid
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Invalid configuration", zap.Error(err))
+	}
+	database.Configure(cfg.Database)
+	core.Configure(cfg)
+	api.Configure(cfg)
+	if cfg.Server.AppKey == config.Default().Server.AppKey {
+		logger.Warn("APP_KEY is the built-in development key; set your own in production")
+	}
+
 	// 2. Initialize Database
 	database.Init()
-	defer database.DB.Close()
+	defer database.Close()
 
 	// 3. Init Worker Pool (Async Start)
-	core.InitPool()
+	core.InitPool(database.Live)
 
 	// 4. EXPERT: Warmup Phase
 	// Block until routers are connected (or timeout)
 	core.GlobalPool.WaitForReady()
+	core.GlobalPool.StartFUP()
+	core.GlobalPool.StartScheduler()
+	core.GlobalPool.WatchRouters()
+
+	// Offline mode: snapshot the inventory, fall back to it if MySQL goes away
+	database.StartSnapshots()
+	database.StartMonitor(core.GlobalPool.Recover)
 
 	// 5. Start API Server (Blocks main thread)
-	api.Start(":8080")
+	api.Start(cfg.Server.Port, database.Live)
 
 	// Block forever
 	select {}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"skynet-net-engine-api/internal/config"
+	"skynet-net-engine-api/internal/core"
 	"skynet-net-engine-api/internal/database"
 	"skynet-net-engine-api/internal/mikrotik"
 	"skynet-net-engine-api/pkg/logger"
@@ -12,12 +14,18 @@ import (
 // SyncUsersFromMikrotik fetches all PPPoE secrets from MikroTik and syncs to database
 func main() {
 	logger.Init()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	database.Configure(cfg.Database)
+	core.Configure(cfg)
 	database.Init()
 
 	log.Println("🔄 Starting user sync from MikroTik...")
 
-	// Get all routers
-	routers, err := database.GetAllRouters()
+	// Get all routers (database and/or ROUTERS_FILE)
+	routers, err := core.LoadRouters(database.Live)
 	if err != nil {
 		log.Fatalf("Failed to fetch routers: %v", err)
 	}
@@ -41,14 +49,13 @@ func main() {
 			continue
 		}
 
-		// Insert into database
-		for _, secret := range secrets {
-			err := database.UpsertUser(secret.Name, router.ID, secret.Profile)
-			if err != nil {
-				logger.Error("Failed to insert user", zap.String("user", secret.Name), zap.Error(err))
-			} else {
-				totalSynced++
-			}
+		// Mirror into database (secrets gone from the router are marked deleted or removed)
+		summary, err := database.Live.SyncUsers(router.ID, secrets, core.SyncDeletePolicy)
+		if err != nil {
+			logger.Error("Failed to sync users", zap.String("router", router.Name), zap.Error(err))
+		} else {
+			totalSynced += summary.Created + summary.Updated + summary.Unchanged
+			log.Printf("   created %d, updated %d, removed %d", summary.Created, summary.Updated, summary.Removed)
 		}
 
 		client.Close()
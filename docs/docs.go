@@ -24,35 +24,127 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
-            "get": {
-                "description": "Checks if the NetEngine Muscle is alive",
+        "/bulk": {
+            "post": {
+                "description": "Runs a list of mixed operations (create_secret, change_profile, isolate, unisolate, kick) across routers\nwith bounded concurrency. Returns a result per item in request order. dry_run only validates and resolves\nrouters; stop_on_error skips the remaining items after the first failure.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Bridge"
+                ],
+                "summary": "Bulk Operations",
+                "parameters": [
+                    {
+                        "description": "Operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BulkSummary"
+                        }
+                    }
+                }
+            }
+        },
+        "/config": {
+            "get": {
+                "description": "Settings the engine is running with, after CONFIG_FILE and environment variables.\nThe API key, snapshot key and passwords in the DSN and webhook URL are redacted.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "System"
                 ],
-                "summary": "Get API Health",
+                "summary": "Get effective configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "$ref": "#/definitions/config.Config"
+                        }
+                    }
+                }
+            }
+        },
+        "/fup/events": {
+            "get": {
+                "description": "Returns the most recent quota exceeded/restored transitions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "FUP"
+                ],
+                "summary": "FUP Transition Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only this username",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FUPEvent"
                             }
                         }
                     }
                 }
             }
         },
-        "/isolate": {
+        "/fup/rules": {
+            "get": {
+                "description": "Returns the per-profile monthly quota rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "FUP"
+                ],
+                "summary": "List FUP Rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FUPRule"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Adds or removes a user from a Firewall Address List",
+                "description": "Sets the monthly quota of a profile. Subscribers over quota are moved to throttle_profile\n(\"throttle\") or put on address_list (\"isolate\") until the next billing cycle (FUP_CYCLE_DAY).",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,17 +152,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Advanced"
+                    "FUP"
                 ],
-                "summary": "Isolate User",
+                "summary": "Create or Update FUP Rule",
                 "parameters": [
                     {
-                        "description": "Isolation Data",
+                        "description": "Rule (keyed by profile)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.IsolateRequest"
+                            "$ref": "#/definitions/models.FUPRule"
                         }
                     }
                 ],
@@ -78,18 +170,15 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.FUPRule"
                         }
                     }
                 }
             }
         },
-        "/router/{id}/health": {
-            "get": {
-                "description": "Returns CPU, Memory, and Uptime",
+        "/fup/rules/{id}": {
+            "delete": {
+                "description": "Removes a rule. Subscribers it already limited are restored at the next billing cycle.",
                 "consumes": [
                     "application/json"
                 ],
@@ -97,13 +186,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitoring"
+                    "FUP"
                 ],
-                "summary": "Get Router Health",
+                "summary": "Delete FUP Rule",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Router ID",
+                        "description": "Rule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -113,15 +202,18 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SystemResource"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/router/{id}/users": {
+        "/fup/states": {
             "get": {
-                "description": "Returns all PPPoE users from database with real-time connection status (connected, isolated, offline)",
+                "description": "Returns subscribers currently throttled or isolated for exceeding their quota",
                 "consumes": [
                     "application/json"
                 ],
@@ -129,34 +221,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitoring"
-                ],
-                "summary": "Get All Users with Status",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Router ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "FUP"
                 ],
+                "summary": "List Limited Subscribers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.UserWithStatus"
+                                "$ref": "#/definitions/models.FUPState"
                             }
                         }
                     }
                 }
             }
         },
-        "/secret": {
+        "/health": {
+            "get": {
+                "description": "Checks if the NetEngine Muscle is alive. While the database is unreachable the status is\n\"degraded\" and \"database\" tells since when and which snapshot the engine is running from, and\nlists the operations that fail until the database is back under \"unavailable\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get API Health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/isolate": {
             "post": {
-                "description": "Adds a new PPPoE secret to a router",
+                "description": "Adds or removes a user from a Firewall Address List. When \"user\" is given instead of \"ip\",\nthe entry follows the subscriber's current IP across reconnects until it is removed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -164,23 +271,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bridge"
+                    "Advanced"
                 ],
-                "summary": "Create PPP Secret",
+                "summary": "Isolate User",
                 "parameters": [
                     {
-                        "description": "Secret Data",
+                        "description": "Isolation Data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.CreateSecretRequest"
+                            "$ref": "#/definitions/api.IsolateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -191,9 +298,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/secret/{user}": {
-            "put": {
-                "description": "Updates the profile of an existing secret",
+        "/jobs": {
+            "get": {
+                "description": "Returns the most recent async jobs",
                 "consumes": [
                     "application/json"
                 ],
@@ -201,24 +308,89 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bridge"
+                    "Jobs"
                 ],
-                "summary": "Change Plan",
+                "summary": "List Jobs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "user",
+                        "description": "queued, running, succeeded or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max jobs (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Job"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Returns the status, progress and result of an async job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    }
+                }
+            }
+        },
+        "/kick": {
+            "post": {
+                "description": "Drops the active PPP session(s) of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Control"
+                ],
+                "summary": "Kick User",
+                "parameters": [
                     {
-                        "description": "Plan Data",
+                        "description": "Kick Data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdatePlanRequest"
+                            "$ref": "#/definitions/api.KickRequest"
                         }
                     }
                 ],
@@ -227,17 +399,15 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/sync/{id}": {
-            "post": {
-                "description": "Triggers an immediate sync of active users",
+        "/monitoring/targets": {
+            "get": {
+                "description": "Returns active PPP sessions across all routers, filtered, sorted and paginated",
                 "consumes": [
                     "application/json"
                 ],
@@ -245,96 +415,2188 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Control"
+                    "Monitoring"
                 ],
-                "summary": "Force Sync Router",
+                "summary": "Get Active Sessions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Router ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only sessions on this router",
+                        "name": "router_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username prefix",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search username or IP",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only IPs inside this network, e.g. 10.10.0.0/16",
+                        "name": "cidr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "username, ip, uptime, router_id or caller_id; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.Page-models_ActiveUser"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.CreateSecretRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "profile",
-                "user"
-            ],
-            "properties": {
+        },
+        "/reports/anomalies": {
+            "get": {
+                "description": "Lists usernames online on several routers at once, MACs logged in as several usernames,\nand recent MAC changes between sessions of the same username (possible account sharing)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Session Anomalies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.AnomalyReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/ip-conflicts": {
+            "get": {
+                "description": "Cross-checks active session addresses and pppoe_users.remote_address fleet-wide for\nstatic IPs pinned on several secrets, addresses held by several sessions, and sessions\nholding another subscriber's static IP",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "IP Address Conflicts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPConflict"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/ip-pools": {
+            "get": {
+                "description": "Returns /ip/pool utilization for every router (refreshed every minute). Pools at or\nabove POOL_ALERT_THRESHOLD percent fire a \"pool.threshold_exceeded\" webhook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "IP Pool Utilization",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPPoolUsage"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/resume": {
+            "post": {
+                "description": "Lifts a suspension and restores the previous profile and enabled state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Advanced"
+                ],
+                "summary": "Resume User",
+                "parameters": [
+                    {
+                        "description": "Resume Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ResumeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Suspension"
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/backup": {
+            "post": {
+                "description": "Creates a /system/backup file on the router. With async=true the backup is queued and\n202 is returned with a job ID to poll at /jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Control"
+                ],
+                "summary": "Backup Router",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Run in the background",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/drift": {
+            "get": {
+                "description": "Compares the router's PPP secrets with pppoe_users: secrets only on the router, only in the DB,\nor on both with a different profile, remote address or enabled flag. It uses the same rules as a\n\"db\" reconciliation: suspended and FUP-throttled users are left out, and an empty profile or remote\naddress in the DB matches anything. format=csv downloads the same rows as a spreadsheet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Secret Drift Report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DriftReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/health": {
+            "get": {
+                "description": "Returns CPU, Memory, and Uptime",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitoring"
+                ],
+                "summary": "Get Router Health",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SystemResource"
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/pools": {
+            "get": {
+                "description": "Returns size, used addresses and utilization of each /ip/pool on the router",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitoring"
+                ],
+                "summary": "Get Router IP Pools",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPPoolUsage"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/reconcile": {
+            "get": {
+                "description": "Returns the result of the router's most recent reconciliation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Last Reconciliation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconcileResult"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Makes the router's /ppp/secret list match the source of truth: pppoe_users (\"db\") or the users in the\nrequest (e.g. from billing). mode \"plan\" only lists the changes, \"apply\" also performs them and \"drift\"\nreports them with a router.drift webhook. Secrets missing from the source are ignored, disabled or\nremoved per \"orphans\". Suspended and FUP-throttled users are left alone. Creating a secret needs a\npassword, so creates from \"db\" are skipped. An apply that would remove or disable secrets because the\nsource is empty, or more than policy.reconcile_max_remove percent of them without \"force\", is refused\nwith 409. With async=true a job ID is returned instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconciliation"
+                ],
+                "summary": "Reconcile Router Secrets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Run in the background",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Mode, source and orphan policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReconcileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconcileResult"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/router/{id}/users": {
+            "get": {
+                "description": "Returns all PPPoE users from database with real-time connection status (connected, isolated, suspended, offline).\nIsolation is derived from the router's isolation address lists (ISOLATION_LISTS)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitoring"
+                ],
+                "summary": "Get All Users with Status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "connected, isolated, suspended or offline",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PPP profile",
+                        "name": "profile",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username prefix",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search username, IP or secret comment (customer ID)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only IPs inside this network, e.g. 10.10.0.0/16",
+                        "name": "cidr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "username, status, profile, ip, uptime or comment; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Page-models_UserWithStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "description": "Returns scheduled jobs, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scheduler"
+                ],
+                "summary": "List Scheduled Jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, done, failed or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only jobs for this subscriber",
+                        "name": "username",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduledJob"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedules a profile change, isolation or kick once at run_at or repeatedly on a cron expression\n(e.g. \"0 22 * * *\" for a night boost). Jobs whose router is offline are retried every minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scheduler"
+                ],
+                "summary": "Schedule Operation",
+                "parameters": [
+                    {
+                        "description": "Operation and timing",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "get": {
+                "description": "Returns one scheduled job with its status, attempts and last error",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scheduler"
+                ],
+                "summary": "Get Scheduled Job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledJob"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels a pending job. Finished jobs cannot be cancelled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scheduler"
+                ],
+                "summary": "Cancel Scheduled Job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/secret": {
+            "post": {
+                "description": "Adds a new PPPoE secret to a router. If the secret already exists, only the fields that differ\nare updated; \"changed\" reports whether the router was modified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bridge"
+                ],
+                "summary": "Create PPP Secret",
+                "parameters": [
+                    {
+                        "description": "Secret Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/secret/{user}": {
+            "put": {
+                "description": "Updates the profile of an existing secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bridge"
+                ],
+                "summary": "Change Plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Plan Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdatePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscribers": {
+            "get": {
+                "description": "Searches every router's sessions (and static addresses) by IP, or sessions and the\ncaller-id/last caller-id of stored secrets by MAC",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscribers"
+                ],
+                "summary": "Find Subscriber by IP or MAC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IP address",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MAC address / caller-id",
+                        "name": "mac",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fetch live traffic for online sessions (default true)",
+                        "name": "traffic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/subscribers/{username}": {
+            "get": {
+                "description": "Searches every router for a username and returns its session, profile, IP, uptime,\nisolation/suspension status and live traffic, one record per router it is on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscribers"
+                ],
+                "summary": "Find Subscriber",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PPPoE username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fetch live traffic for online sessions (default true)",
+                        "name": "traffic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/subscribers/{username}/sessions": {
+            "get": {
+                "description": "Returns the sessions of a username overlapping a time window, newest first, with\ncounts that help spot flapping lines",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscribers"
+                ],
+                "summary": "Subscriber Connection Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PPPoE username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start, RFC3339 or YYYY-MM-DD (default 7 days ago)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end, RFC3339 or YYYY-MM-DD (default now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only sessions on this router",
+                        "name": "router_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max sessions (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/subscribers/{username}/usage": {
+            "get": {
+                "description": "Returns bytes used per hour, day or month, collected from queue byte counters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscribers"
+                ],
+                "summary": "Subscriber Data Usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PPPoE username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "hourly, daily (default) or monthly",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start, RFC3339 or YYYY-MM-DD (default 24h / 30d / 12 months ago)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end, RFC3339 or YYYY-MM-DD (default now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only usage on this router",
+                        "name": "router_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/suspend": {
+            "post": {
+                "description": "Suspends a subscriber by disabling the secret (\"disable\") or moving it to a captive\nprofile (\"isolir\"). The previous profile is saved so that resume restores it exactly.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Advanced"
+                ],
+                "summary": "Suspend User",
+                "parameters": [
+                    {
+                        "description": "Suspension Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SuspendRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Suspension"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/{id}": {
+            "post": {
+                "description": "Copies the router's PPP secrets into pppoe_users in one transaction and returns the created,\nupdated and removed counts. Rows whose secret is gone are marked deleted or removed per\nSYNC_DELETE_POLICY. With async=true the sync is queued and 202 is returned with a job ID to poll at /jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Control"
+                ],
+                "summary": "Force Sync Router",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Router ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Run in the background",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncSummary"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BulkRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "concurrency": {
+                    "description": "Default 10, max 50",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 5000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.Operation"
+                    }
+                },
+                "stop_on_error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.CreateSecretRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "profile",
+                "user"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "local_ip": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.IsolateRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "add or remove",
+                    "type": "string",
+                    "enum": [
+                        "add",
+                        "remove"
+                    ]
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "list": {
+                    "description": "Default to \"ISOLATED\" if empty",
+                    "type": "string"
+                },
+                "router_id": {
+                    "description": "Optional; resolved from the user when omitted",
+                    "type": "integer"
+                },
+                "user": {
+                    "description": "Isolate by username; the entry follows the user's current IP",
+                    "type": "string"
+                }
+            }
+        },
+        "api.KickRequest": {
+            "type": "object",
+            "required": [
+                "user"
+            ],
+            "properties": {
+                "router_id": {
+                    "description": "Optional; resolved from the user when omitted",
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Page-models_ActiveUser": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Items in this page",
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ActiveUser"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Items matching the filters, across all pages",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.Page-models_UserWithStatus": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Items in this page",
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserWithStatus"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Items matching the filters, across all pages",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ReconcileRequest": {
+            "type": "object",
+            "properties": {
+                "force": {
+                    "description": "Apply past policy.reconcile_max_remove",
+                    "type": "boolean"
+                },
+                "mode": {
+                    "description": "Default \"plan\"",
+                    "type": "string",
+                    "enum": [
+                        "plan",
+                        "apply",
+                        "drift"
+                    ]
+                },
+                "orphans": {
+                    "description": "Default \"ignore\"",
+                    "type": "string",
+                    "enum": [
+                        "ignore",
+                        "disable",
+                        "remove"
+                    ]
+                },
+                "source": {
+                    "description": "Default \"db\"",
+                    "type": "string",
+                    "enum": [
+                        "db",
+                        "request"
+                    ]
+                },
+                "users": {
+                    "description": "Source \"request\" only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DesiredSecret"
+                    }
+                }
+            }
+        },
+        "api.ResumeRequest": {
+            "type": "object",
+            "required": [
+                "user"
+            ],
+            "properties": {
+                "router_id": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ScheduleRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "user"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create_secret",
+                        "change_profile",
+                        "isolate",
+                        "unisolate",
+                        "kick"
+                    ]
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "cron": {
+                    "description": "Recurring, 5-field cron in server local time",
+                    "type": "string"
+                },
+                "list": {
+                    "description": "isolate, unisolate; defaults to \"ISOLATED\"",
+                    "type": "string"
+                },
+                "local_ip": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "create_secret, change_profile",
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "router_id": {
+                    "description": "Optional; resolved from the user when omitted",
+                    "type": "integer"
+                },
+                "run_at": {
+                    "description": "One-shot, RFC3339",
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SuspendRequest": {
+            "type": "object",
+            "required": [
+                "strategy",
+                "user"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "isolir strategy only; defaults to policy.suspend_profile",
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string",
+                    "enum": [
+                        "disable",
+                        "isolir"
+                    ]
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdatePlanRequest": {
+            "type": "object",
+            "required": [
+                "profile"
+            ],
+            "properties": {
+                "profile": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.Config": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "$ref": "#/definitions/config.Database"
+                },
+                "policy": {
+                    "$ref": "#/definitions/config.Policy"
+                },
+                "routers": {
+                    "$ref": "#/definitions/config.Routers"
+                },
+                "server": {
+                    "$ref": "#/definitions/config.Server"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/config.Webhook"
+                },
+                "workers": {
+                    "$ref": "#/definitions/config.Workers"
+                }
+            }
+        },
+        "config.Database": {
+            "type": "object",
+            "properties": {
+                "auto_migrate": {
+                    "type": "boolean"
+                },
+                "driver": {
+                    "description": "\"mysql\" or \"sqlite\"",
+                    "type": "string"
+                },
+                "dsn": {
+                    "description": "SQLite: file path, default netengine.db",
+                    "type": "string"
+                },
+                "snapshot_key": {
+                    "description": "Snapshots are off without it",
+                    "type": "string"
+                },
+                "snapshot_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.Policy": {
+            "type": "object",
+            "properties": {
+                "allow_mac_change_users": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "allow_multi_session_users": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "allow_shared_macs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fup_cycle_day": {
+                    "type": "integer"
+                },
+                "isolation_lists": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pool_alert_threshold": {
+                    "description": "Percent",
+                    "type": "number"
+                },
+                "reconcile_max_remove": {
+                    "description": "Percent of a router's secrets",
+                    "type": "number"
+                },
+                "suspend_profile": {
+                    "type": "string"
+                },
+                "sync_delete_policy": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.Routers": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "\"db\", \"file\" or \"merge\" (default with a file)",
+                    "type": "string"
+                }
+            }
+        },
+        "config.Server": {
+            "type": "object",
+            "properties": {
+                "app_key": {
+                    "description": "X-App-Key for /api/v1",
+                    "type": "string"
+                },
+                "cors_origin": {
+                    "type": "string"
+                },
+                "idempotency_ttl": {
+                    "type": "string"
+                },
+                "port": {
+                    "description": "\":8080\", \"8080\" or \"host:port\"",
+                    "type": "string"
+                }
+            }
+        },
+        "config.Webhook": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "description": "Empty disables webhooks",
+                    "type": "string"
+                }
+            }
+        },
+        "config.Workers": {
+            "type": "object",
+            "properties": {
+                "command_buffer": {
+                    "description": "Queued commands per router",
+                    "type": "integer"
+                },
+                "connect_retry": {
+                    "description": "Wait after a failed connect",
+                    "type": "string"
+                },
+                "metrics_interval": {
+                    "type": "string"
+                },
+                "reconnect_delay": {
+                    "description": "Wait after a dropped connection",
+                    "type": "string"
+                },
+                "warmup_timeout": {
+                    "description": "How long startup waits for routers",
+                    "type": "string"
+                }
+            }
+        },
+        "core.Anomaly": {
+            "type": "object",
+            "properties": {
+                "detected_at": {
+                    "type": "string"
+                },
+                "mac": {
+                    "type": "string"
+                },
+                "previous_mac": {
+                    "type": "string"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ActiveUser"
+                    }
+                },
+                "type": {
+                    "$ref": "#/definitions/core.AnomalyType"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.AnomalyReport": {
+            "type": "object",
+            "properties": {
+                "duplicate_sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Anomaly"
+                    }
+                },
+                "mac_changes": {
+                    "description": "Most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Anomaly"
+                    }
+                },
+                "shared_macs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Anomaly"
+                    }
+                }
+            }
+        },
+        "core.AnomalyType": {
+            "type": "string",
+            "enum": [
+                "duplicate_session",
+                "mac_changed",
+                "shared_mac"
+            ],
+            "x-enum-comments": {
+                "AnomalyDuplicateSession": "Same username online on several routers",
+                "AnomalyMACChanged": "Username reconnected from a different MAC",
+                "AnomalySharedMAC": "One MAC online as several usernames"
+            },
+            "x-enum-descriptions": [
+                "Same username online on several routers",
+                "Username reconnected from a different MAC",
+                "One MAC online as several usernames"
+            ],
+            "x-enum-varnames": [
+                "AnomalyDuplicateSession",
+                "AnomalyMACChanged",
+                "AnomalySharedMAC"
+            ]
+        },
+        "core.BulkSummary": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OperationResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ActiveUser": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "IP Address",
+                    "type": "string"
+                },
+                "caller_id": {
+                    "description": "MAC Address",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "uptime": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DesiredSecret": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "enabled": {
+                    "description": "Empty leaves the router's value alone",
+                    "type": "boolean"
+                },
+                "password": {
+                    "description": "Needed to create missing secrets",
+                    "type": "string"
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "remote_address": {
+                    "description": "Empty leaves the router's value alone",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DriftReport": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "type": "string"
+                },
+                "in_sync": {
+                    "type": "integer"
+                },
+                "managed": {
+                    "description": "Suspended or FUP-limited users left out",
+                    "type": "integer"
+                },
+                "mismatched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecretDrift"
+                    }
+                },
+                "only_db": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecretDrift"
+                    }
+                },
+                "only_router": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecretDrift"
+                    }
+                },
+                "router_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FUPEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "\"exceeded\" or \"restored\"",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "quota_bytes": {
+                    "type": "integer"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "usage_bytes": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FUPRule": {
+            "type": "object",
+            "required": [
+                "action",
+                "profile",
+                "quota_bytes"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "throttle",
+                        "isolate"
+                    ]
+                },
+                "address_list": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "quota_bytes": {
+                    "type": "integer"
+                },
+                "throttle_profile": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FUPState": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "address_list": {
+                    "type": "string"
+                },
+                "applied_at": {
+                    "type": "string"
+                },
+                "cycle_start": {
+                    "type": "string"
+                },
+                "original_profile": {
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "throttle_profile": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IPConflict": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "\"static\", \"session\" or \"static_in_use\"",
+                    "type": "string"
+                },
+                "secrets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IPConflictOwner"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ActiveUser"
+                    }
+                }
+            }
+        },
+        "models.IPConflictOwner": {
+            "type": "object",
+            "properties": {
+                "router_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IPPoolUsage": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "ranges": {
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                },
+                "utilization": {
+                    "description": "Percent of Size in use",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Percent",
+                    "type": "integer"
+                },
+                "result": {},
+                "router_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Operation": {
+            "type": "object",
+            "required": [
+                "action",
+                "user"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create_secret",
+                        "change_profile",
+                        "isolate",
+                        "unisolate",
+                        "kick"
+                    ]
+                },
                 "comment": {
                     "type": "string"
                 },
+                "list": {
+                    "description": "isolate, unisolate; defaults to \"ISOLATED\"",
+                    "type": "string"
+                },
                 "local_ip": {
+                    "description": "create_secret",
                     "type": "string"
                 },
                 "password": {
+                    "description": "create_secret",
                     "type": "string"
                 },
                 "profile": {
+                    "description": "create_secret, change_profile",
                     "type": "string"
                 },
                 "remote_ip": {
+                    "description": "create_secret",
                     "type": "string"
                 },
+                "router_id": {
+                    "description": "Optional; resolved from the user when omitted",
+                    "type": "integer"
+                },
                 "user": {
                     "type": "string"
                 }
             }
         },
-        "api.IsolateRequest": {
+        "models.OperationResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "result": {},
+                "router_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "\"ok\", \"error\", \"skipped\" or \"planned\" (dry run)",
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconcileResult": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecretChange"
+                    }
+                },
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "desired": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "in_sync": {
+                    "type": "integer"
+                },
+                "managed": {
+                    "description": "Suspended or FUP-limited users left alone",
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScheduledJob": {
             "type": "object",
             "required": [
                 "action",
-                "ip"
+                "user"
             ],
             "properties": {
                 "action": {
-                    "description": "add or remove",
                     "type": "string",
                     "enum": [
-                        "add",
-                        "remove"
+                        "create_secret",
+                        "change_profile",
+                        "isolate",
+                        "unisolate",
+                        "kick"
                     ]
                 },
+                "attempts": {
+                    "description": "Failed attempts of the current run",
+                    "type": "integer"
+                },
                 "comment": {
                     "type": "string"
                 },
-                "ip": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
                     "type": "string"
                 },
                 "list": {
-                    "description": "Default to \"ISOLATED\" if empty",
+                    "description": "isolate, unisolate; defaults to \"ISOLATED\"",
+                    "type": "string"
+                },
+                "local_ip": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "description": "Empty once the job is finished",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "create_secret, change_profile",
+                    "type": "string"
+                },
+                "remote_ip": {
+                    "description": "create_secret",
+                    "type": "string"
+                },
+                "router_id": {
+                    "description": "Optional; resolved from the user when omitted",
+                    "type": "integer"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdatePlanRequest": {
+        "models.SecretChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "RouterOS properties to set",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "apply mode: \"applied\", \"failed\" or \"skipped\"",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SecretDetails": {
+            "type": "object",
+            "properties": {
+                "caller_id": {
+                    "description": "Caller-id (MAC) the secret is bound to",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "Usually the billing customer ID",
+                    "type": "string"
+                },
+                "last_caller_id": {
+                    "description": "RouterOS v7",
+                    "type": "string"
+                },
+                "last_logged_out": {
+                    "description": "RouterOS v7",
+                    "type": "string"
+                },
+                "local_address": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SecretDrift": {
+            "type": "object",
+            "properties": {
+                "db": {
+                    "$ref": "#/definitions/models.SecretState"
+                },
+                "fields": {
+                    "description": "Mismatched fields: profile, remote_address, enabled",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "router": {
+                    "$ref": "#/definitions/models.SecretState"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SecretState": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "remote_address": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Suspension": {
             "type": "object",
-            "required": [
-                "profile"
-            ],
             "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "previous_disabled": {
+                    "type": "boolean"
+                },
+                "previous_profile": {
+                    "type": "string"
+                },
                 "profile": {
+                    "description": "Profile applied while suspended (isolir strategy)",
+                    "type": "string"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SyncSummary": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "delete_policy": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "Set once the transaction committed",
+                    "type": "string"
+                },
+                "removed": {
+                    "type": "integer"
+                },
+                "router_id": {
+                    "type": "integer"
+                },
+                "started_at": {
                     "type": "string"
+                },
+                "total": {
+                    "description": "Secrets on the router",
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
                 }
             }
         },
@@ -368,13 +2630,25 @@ const docTemplate = `{
                 "ip": {
                     "type": "string"
                 },
+                "isolation_comment": {
+                    "type": "string"
+                },
+                "isolation_list": {
+                    "type": "string"
+                },
                 "profile": {
                     "type": "string"
                 },
+                "secret": {
+                    "$ref": "#/definitions/models.SecretDetails"
+                },
                 "status": {
-                    "description": "\"connected\", \"isolated\", or \"offline\"",
+                    "description": "\"connected\", \"isolated\", \"suspended\", or \"offline\"",
                     "type": "string"
                 },
+                "suspension": {
+                    "$ref": "#/definitions/models.Suspension"
+                },
                 "uptime": {
                     "type": "string"
                 },
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"skynet-net-engine-api/internal/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunBulk godoc
+// @Summary      Bulk Operations
+// @Description  Runs a list of mixed operations (create_secret, change_profile, isolate, unisolate, kick) across routers
+// @Description  with bounded concurrency. Returns a result per item in request order. dry_run only validates and resolves
+// @Description  routers; stop_on_error skips the remaining items after the first failure.
+// @Tags         Bridge
+// @Accept       json
+// @Produce      json
+// @Param        request body BulkRequest true "Operations"
+// @Success      200  {object}  core.BulkSummary
+// @Router       /bulk [post]
+func RunBulk(c *gin.Context) {
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary := core.GlobalPool.RunBulk(req.Operations, core.BulkOptions{
+		DryRun:      req.DryRun,
+		StopOnError: req.StopOnError,
+		Concurrency: req.Concurrency,
+	}, commandTimeout)
+
+	c.JSON(http.StatusOK, summary)
+}
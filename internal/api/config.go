@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"skynet-net-engine-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaults seed the settings below until Configure is called
+var defaults = config.Default()
+
+// API settings, set by Configure
+var (
+	CORSOrigin     = defaults.Server.CORSOrigin
+	AppKey         = defaults.Server.AppKey
+	SuspendProfile = defaults.Policy.SuspendProfile
+
+	effective = defaults // Shown by GetConfig
+)
+
+// Configure applies the API settings; call it before Start
+func Configure(c *config.Config) {
+	CORSOrigin, AppKey = c.Server.CORSOrigin, c.Server.AppKey
+	SuspendProfile = c.Policy.SuspendProfile
+	IdempotencyTTL = time.Duration(c.Server.IdempotencyTTL)
+	effective = c
+}
+
+// GetConfig godoc
+// @Summary      Get effective configuration
+// @Description  Settings the engine is running with, after CONFIG_FILE and environment variables.
+// @Description  The API key, snapshot key and passwords in the DSN and webhook URL are redacted.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  config.Config
+// @Router       /config [get]
+func GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, effective.Redacted())
+}
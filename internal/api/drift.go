@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRouterDrift godoc
+// @Summary      Secret Drift Report
+// @Description  Compares the router's PPP secrets with pppoe_users: secrets only on the router, only in the DB,
+// @Description  or on both with a different profile, remote address or enabled flag. It uses the same rules as a
+// @Description  "db" reconciliation: suspended and FUP-throttled users are left out, and an empty profile or remote
+// @Description  address in the DB matches anything. format=csv downloads the same rows as a spreadsheet.
+// @Tags         Reconciliation
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param        id      path   int     true   "Router ID"
+// @Param        format  query  string  false  "json (default) or csv"
+// @Success      200  {object}  models.DriftReport
+// @Router       /router/{id}/drift [get]
+func GetRouterDrift(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Router ID"})
+		return
+	}
+
+	worker := core.GlobalPool.GetWorker(id)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found"})
+		return
+	}
+
+	res, err := worker.Execute(core.CmdGetSecrets, nil, commandTimeout)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+	report, err := worker.Drift(res.([]models.PPPoESecret))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users from database"})
+		return
+	}
+	report.RouterID = id
+	report.GeneratedAt = time.Now()
+
+	if c.Query("format") == "csv" {
+		writeDriftCSV(c, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// writeDriftCSV writes one row per drifted secret
+func writeDriftCSV(c *gin.Context, report models.DriftReport) {
+	filename := fmt.Sprintf("router-%d-drift-%s.csv", report.RouterID, report.GeneratedAt.Format("20060102_150405"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"category", "username", "mismatched", "router_profile", "db_profile",
+		"router_remote_address", "db_remote_address", "router_enabled", "db_enabled"})
+
+	rows := []struct {
+		category string
+		entries  []models.SecretDrift
+	}{
+		{"only_router", report.OnlyRouter},
+		{"only_db", report.OnlyDB},
+		{"mismatched", report.Mismatched},
+	}
+	for _, group := range rows {
+		for _, d := range group.entries {
+			record := []string{group.category, d.Username, strings.Join(d.Fields, ";")}
+			record = append(record, stateColumns(d.Router, d.DB)...)
+			w.Write(record)
+		}
+	}
+	w.Flush()
+}
+
+// stateColumns interleaves the router and DB values; absent sides stay empty
+func stateColumns(router, db *models.SecretState) []string {
+	cols := func(s *models.SecretState) (string, string, string) {
+		if s == nil {
+			return "", "", ""
+		}
+		return s.Profile, s.RemoteAddress, strconv.FormatBool(s.Enabled)
+	}
+	rp, ra, re := cols(router)
+	dp, da, de := cols(db)
+	return []string{rp, dp, ra, da, re, de}
+}
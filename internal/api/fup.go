@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFUPRules godoc
+// @Summary      List FUP Rules
+// @Description  Returns the per-profile monthly quota rules
+// @Tags         FUP
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  models.FUPRule
+// @Router       /fup/rules [get]
+func GetFUPRules(c *gin.Context) {
+	rules, err := store.GetFUPRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch FUP rules"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// SaveFUPRule godoc
+// @Summary      Create or Update FUP Rule
+// @Description  Sets the monthly quota of a profile. Subscribers over quota are moved to throttle_profile
+// @Description  ("throttle") or put on address_list ("isolate") until the next billing cycle (FUP_CYCLE_DAY).
+// @Tags         FUP
+// @Accept       json
+// @Produce      json
+// @Param        request body models.FUPRule true "Rule (keyed by profile)"
+// @Success      200  {object}  models.FUPRule
+// @Router       /fup/rules [post]
+func SaveFUPRule(c *gin.Context) {
+	rule := models.FUPRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := store.SaveFUPRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save FUP rule"})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteFUPRule godoc
+// @Summary      Delete FUP Rule
+// @Description  Removes a rule. Subscribers it already limited are restored at the next billing cycle.
+// @Tags         FUP
+// @Accept       json
+// @Produce      json
+// @Param        id   path  int  true  "Rule ID"
+// @Success      200  {object}  map[string]string
+// @Router       /fup/rules/{id} [delete]
+func DeleteFUPRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Rule ID"})
+		return
+	}
+
+	if err := store.DeleteFUPRule(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete FUP rule"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "Rule Deleted"})
+}
+
+// GetFUPStates godoc
+// @Summary      List Limited Subscribers
+// @Description  Returns subscribers currently throttled or isolated for exceeding their quota
+// @Tags         FUP
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  models.FUPState
+// @Router       /fup/states [get]
+func GetFUPStates(c *gin.Context) {
+	states, err := store.GetFUPStates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch FUP states"})
+		return
+	}
+	c.JSON(http.StatusOK, states)
+}
+
+// GetFUPEvents godoc
+// @Summary      FUP Transition Log
+// @Description  Returns the most recent quota exceeded/restored transitions
+// @Tags         FUP
+// @Accept       json
+// @Produce      json
+// @Param        username  query  string  false  "Only this username"
+// @Param        limit     query  int     false  "Max events (default 100, max 1000)"
+// @Success      200  {array}  models.FUPEvent
+// @Router       /fup/events [get]
+func GetFUPEvents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	events, err := store.GetFUPEvents(c.Query("username"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch FUP events"})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain sets up the logger once, since webhook goroutines from earlier tests
+// may still be logging, and turns webhooks off so no test posts to a real URL
+func TestMain(m *testing.M) {
+	logger.Init()
+	core.WebhookURL = ""
+	os.Exit(m.Run())
+}
+
+// useTestStore gives the handlers a migrated in-memory SQLite store and returns it
+func useTestStore(t *testing.T) database.Store {
+	t.Helper()
+
+	st, err := database.Open(database.DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	_, err = st.MigrateUp(0)
+	require.NoError(t, err)
+
+	prev := store
+	store = st
+	t.Cleanup(func() {
+		store = prev
+		st.Close()
+	})
+	return st
+}
+
+func TestFUPRulesRoundTrip(t *testing.T) {
+	useTestStore(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/fup/rules", GetFUPRules)
+	r.POST("/fup/rules", SaveFUPRule)
+
+	for _, quota := range []string{"1000", "2000"} {
+		body := `{"profile":"10M","quota_bytes":` + quota + `,"action":"throttle","throttle_profile":"1M","enabled":true}`
+		req, _ := http.NewRequest("POST", "/fup/rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req, _ := http.NewRequest("GET", "/fup/rules", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"quota_bytes":2000`)
+	assert.NotContains(t, w.Body.String(), `"quota_bytes":1000`)
+}
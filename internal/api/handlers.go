@@ -14,24 +14,34 @@ import (
 
 // HealthCheck godoc
 // @Summary      Get API Health
-// @Description  Checks if the NetEngine Muscle is alive
+// @Description  Checks if the NetEngine Muscle is alive. While the database is unreachable the status is
+// @Description  "degraded" and "database" tells since when and which snapshot the engine is running from, and
+// @Description  lists the operations that fail until the database is back under "unavailable".
 // @Tags         System
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  map[string]string
+// @Success      200  {object}  map[string]interface{}
 // @Router       /health [get]
 func HealthCheck(c *gin.Context) {
+	if state := database.Offline(); state.Degraded {
+		c.JSON(http.StatusOK, gin.H{"status": "degraded", "muscle": "alive", "database": state})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok", "muscle": "alive"})
 }
 
 // SyncRouter godoc
 // @Summary      Force Sync Router
-// @Description  Triggers an immediate sync of active users
+// @Description  Copies the router's PPP secrets into pppoe_users in one transaction and returns the created,
+// @Description  updated and removed counts. Rows whose secret is gone are marked deleted or removed per
+// @Description  SYNC_DELETE_POLICY. With async=true the sync is queued and 202 is returned with a job ID to poll at /jobs/{id}.
 // @Tags         Control
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Router ID"
-// @Success      200  {object}  map[string]string
+// @Param        id     path      int   true   "Router ID"
+// @Param        async  query     bool  false  "Run in the background"
+// @Success      200  {object}  models.SyncSummary
+// @Success      202  {object}  map[string]interface{}
 // @Router       /sync/{id} [post]
 func SyncRouter(c *gin.Context) {
 	idStr := c.Param("id")
@@ -47,31 +57,128 @@ func SyncRouter(c *gin.Context) {
 		return
 	}
 
-	// Send Async Command
-	cmd := core.Command{
-		Type: core.CmdSync,
-		Result: make(chan interface{}),
+	if wantsAsync(c) {
+		submitJob(c, "sync", worker, func(progress func(int)) (interface{}, error) {
+			return worker.ExecuteWithProgress(core.CmdSync, nil, core.JobTimeout, progress)
+		})
+		return
 	}
-	
-	// Non-blocking send
-	select {
-	case worker.CmdChan <- cmd:
-		// Wait for ack
-		<-cmd.Result
-		c.JSON(http.StatusOK, gin.H{"status": "Sync command sent"})
-	default:
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Worker busy or offline"})
+
+	res, err := worker.Execute(core.CmdSync, nil, commandTimeout)
+	if err != nil {
+		respondCommandError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, res)
 }
 
+// KickUser godoc
+// @Summary      Kick User
+// @Description  Drops the active PPP session(s) of a user
+// @Tags         Control
+// @Accept       json
+// @Produce      json
+// @Param        request body KickRequest true "Kick Data"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /kick [post]
 func KickUser(c *gin.Context) {
-	// TODO: Implement Kick Logic parsing body
-	c.JSON(http.StatusNotImplemented, gin.H{"status": "TODO"})
+	var req KickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	worker := resolveWorker(req.RouterID, req.User)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found for request"})
+		return
+	}
+
+	res, err := worker.Execute(core.CmdKick, req.User, commandTimeout)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "User Kicked", "user": req.User, "router_id": worker.Router.ID, "sessions": res})
+}
+
+// SuspendUser godoc
+// @Summary      Suspend User
+// @Description  Suspends a subscriber by disabling the secret ("disable") or moving it to a captive
+// @Description  profile ("isolir"). The previous profile is saved so that resume restores it exactly.
+// @Tags         Advanced
+// @Accept       json
+// @Produce      json
+// @Param        request body SuspendRequest true "Suspension Data"
+// @Success      200  {object}  models.Suspension
+// @Router       /suspend [post]
+func SuspendUser(c *gin.Context) {
+	var req SuspendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Profile == "" {
+		req.Profile = SuspendProfile
+	}
+
+	worker := resolveWorker(req.RouterID, req.User)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found for request"})
+		return
+	}
+
+	res, err := worker.Execute(core.CmdSuspend, map[string]string{
+		"user": req.User,
+		"strategy": req.Strategy,
+		"profile": req.Profile,
+		"comment": req.Comment,
+	}, commandTimeout)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ResumeUser godoc
+// @Summary      Resume User
+// @Description  Lifts a suspension and restores the previous profile and enabled state
+// @Tags         Advanced
+// @Accept       json
+// @Produce      json
+// @Param        request body ResumeRequest true "Resume Data"
+// @Success      200  {object}  models.Suspension
+// @Router       /resume [post]
+func ResumeUser(c *gin.Context) {
+	var req ResumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	worker := resolveWorker(req.RouterID, req.User)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found for request"})
+		return
+	}
+
+	res, err := worker.Execute(core.CmdResume, req.User, commandTimeout)
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
 }
 
 // CreateSecret godoc
 // @Summary      Create PPP Secret
-// @Description  Adds a new PPPoE secret to a router
+// @Description  Adds a new PPPoE secret to a router. If the secret already exists, only the fields that differ
+// @Description  are updated; "changed" reports whether the router was modified.
 // @Tags         Bridge
 // @Accept       json
 // @Produce      json
@@ -101,25 +208,17 @@ func CreateSecret(c *gin.Context) {
 		return
 	}
 
-	cmd := core.Command{
-		Type: core.CmdCreateSecret,
-		Payload: map[string]string{
-			"user": req.User, "password": req.Password, "profile": req.Profile,
-			"local_ip": req.LocalIP, "remote_ip": req.RemoteIP, "comment": req.Comment,
-		},
-		Error: make(chan error),
-		Result: make(chan interface{}),
-	}
-
-	worker.CmdChan <- cmd
-	
-	err := <-cmd.Error
+	res, err := worker.Execute(core.CmdCreateSecret, map[string]string{
+		"user": req.User, "password": req.Password, "profile": req.Profile,
+		"local_ip": req.LocalIP, "remote_ip": req.RemoteIP, "comment": req.Comment,
+	}, commandTimeout)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondCommandError(c, err)
 		return
 	}
 	
-	c.JSON(http.StatusCreated, gin.H{"status": "Secret Created", "user": req.User})
+	// Retries find the secret already in place and report changed=false
+	c.JSON(http.StatusCreated, gin.H{"status": "Secret Created", "user": req.User, "changed": changed(res)})
 }
 
 // UpdatePlan godoc
@@ -142,30 +241,27 @@ func UpdatePlan(c *gin.Context) {
 
 	workerID := 1 // MVP Hardcode
 	worker := core.GlobalPool.GetWorker(workerID)
-	
-	cmd := core.Command{
-		Type: core.CmdUpdateSecret,
-		Payload: map[string]string{
-			"user": user,
-			"profile": req.Profile,
-		},
-		Error: make(chan error),
-		Result: make(chan interface{}),
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router 1 Not Found (MVP Hardcode)"})
+		return
 	}
 	
-	worker.CmdChan <- cmd
-	err := <-cmd.Error
+	res, err := worker.Execute(core.CmdUpdateSecret, map[string]string{
+		"user": user,
+		"profile": req.Profile,
+	}, commandTimeout)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondCommandError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "Plan Updated", "user": user, "profile": req.Profile})
+	c.JSON(http.StatusOK, gin.H{"status": "Plan Updated", "user": user, "profile": req.Profile, "changed": changed(res)})
 }
 
 // IsolateUser godoc
 // @Summary      Isolate User
-// @Description  Adds or removes a user from a Firewall Address List
+// @Description  Adds or removes a user from a Firewall Address List. When "user" is given instead of "ip",
+// @Description  the entry follows the subscriber's current IP across reconnects until it is removed.
 // @Tags         Advanced
 // @Accept       json
 // @Produce      json
@@ -183,56 +279,87 @@ func IsolateUser(c *gin.Context) {
 		req.List = "ISOLATED"
 	}
 
-	workerID := 1 // MVP
-	worker := core.GlobalPool.GetWorker(workerID)
-	
-	cmd := core.Command{
-		Type: core.CmdIsolate,
-		Payload: map[string]string{
-			"ip": req.IP,
-			"list": req.List,
-			"action": req.Action,
-			"comment": req.Comment,
-		},
-		Error: make(chan error),
-		Result: make(chan interface{}),
+	worker := resolveWorker(req.RouterID, req.User)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found for request"})
+		return
 	}
 	
-	worker.CmdChan <- cmd
-	err := <-cmd.Error
+	res, err := worker.Execute(core.CmdIsolate, map[string]string{
+		"ip": req.IP,
+		"user": req.User,
+		"list": req.List,
+		"action": req.Action,
+		"comment": req.Comment,
+	}, commandTimeout)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondCommandError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "Isolation Updated", "ip": req.IP, "action": req.Action})
+	// Username isolations report the IP they resolved to
+	ip := req.IP
+	if resolved, ok := res.(map[string]interface{}); ok {
+		ip, _ = resolved["ip"].(string)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "Isolation Updated", "ip": ip, "user": req.User, "router_id": worker.Router.ID, "action": req.Action, "changed": changed(res)})
 }
 
+// GetTargets godoc
+// @Summary      Get Active Sessions
+// @Description  Returns active PPP sessions across all routers, filtered, sorted and paginated
+// @Tags         Monitoring
+// @Accept       json
+// @Produce      json
+// @Param        router_id  query  int     false  "Only sessions on this router"
+// @Param        prefix     query  string  false  "Username prefix"
+// @Param        q          query  string  false  "Search username or IP"
+// @Param        cidr       query  string  false  "Only IPs inside this network, e.g. 10.10.0.0/16"
+// @Param        sort       query  string  false  "username, ip, uptime, router_id or caller_id; prefix with - for descending"
+// @Param        limit      query  int     false  "Page size (default 100, max 1000)"
+// @Param        cursor     query  string  false  "next_cursor from the previous page"
+// @Success      200  {object}  Page[models.ActiveUser]
+// @Router       /monitoring/targets [get]
 func GetTargets(c *gin.Context) {
-	requestID := c.Query("request_id") // Optional tracking
-	targets := core.GlobalPool.GetAllTargets()
-	
-	// Add metadata if needed, but array is efficient
-	c.JSON(http.StatusOK, targets)
-	
-	// Logging heavy usage
-	if requestID != "" {
-		// logger.Debug("Targets fetched", zap.String("rid", requestID), zap.Int("count", len(targets)))
+	query, err := parseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+
+	targets := core.GlobalPool.GetAllTargets()
+	c.JSON(http.StatusOK, applyListQuery(targets, query, targetField))
 }
 
 // GetAllUsers godoc
 // @Summary      Get All Users with Status
-// @Description  Returns all PPPoE users from database with real-time connection status (connected, isolated, offline)
+// @Description  Returns all PPPoE users from database with real-time connection status (connected, isolated, suspended, offline).
+// @Description  Isolation is derived from the router's isolation address lists (ISOLATION_LISTS)
 // @Tags         Monitoring
 // @Accept       json
 // @Produce      json
-// @Param        id path int true "Router ID"
-// @Success      200  {array}  models.UserWithStatus
+// @Param        id       path   int     true   "Router ID"
+// @Param        status   query  string  false  "connected, isolated, suspended or offline"
+// @Param        profile  query  string  false  "PPP profile"
+// @Param        prefix   query  string  false  "Username prefix"
+// @Param        q        query  string  false  "Search username, IP or secret comment (customer ID)"
+// @Param        cidr     query  string  false  "Only IPs inside this network, e.g. 10.10.0.0/16"
+// @Param        sort     query  string  false  "username, status, profile, ip, uptime or comment; prefix with - for descending"
+// @Param        limit    query  int     false  "Page size (default 100, max 1000)"
+// @Param        cursor   query  string  false  "next_cursor from the previous page"
+// @Success      200  {object}  Page[models.UserWithStatus]
 // @Router       /router/{id}/users [get]
 func GetAllUsers(c *gin.Context) {
 	idStr := c.Param("id")
 	routerID, _ := strconv.Atoi(idStr)
+
+	query, err := parseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.RouterID = 0 // Already scoped by the path
 	
 	// 1. Get active sessions from worker cache (PRIMARY SOURCE)
 	worker := core.GlobalPool.GetWorker(routerID)
@@ -246,8 +373,9 @@ func GetAllUsers(c *gin.Context) {
 	worker.Lock.RUnlock()
 	
 	// 2. Get DB users for enrichment (SECONDARY SOURCE)
-	dbUsers, _ := database.GetUsersByRouter(routerID)
-	// We ignore error here because we still want to show active users even if DB fails
+	dbUsers, _ := store.GetUsersByRouter(routerID)
+	suspensions, _ := store.GetSuspensionsByRouter(routerID)
+	// We ignore errors here because we still want to show active users even if DB fails
 	
 	// 3. Build response: Start with Active Sessions
 	result := []models.UserWithStatus{}
@@ -259,41 +387,60 @@ func GetAllUsers(c *gin.Context) {
 	for _, session := range activeSessions {
 		// Try to find profile from DB, default to "unknown" or empty
 		profile := "unknown"
+		var secret *models.SecretDetails
 		if p, exists := dbUsers[session.Name]; exists {
 			profile = p.Profile
+			secret = &p.SecretDetails
 		}
 		
-		result = append(result, models.UserWithStatus{
+		user := models.UserWithStatus{
 			Username: session.Name,
-			Status:   "connected",
 			IP:       session.Address,
 			Uptime:   session.Uptime,
 			Profile:  profile,
-		})
+			Secret:   secret,
+		}
+		// Captive (isolir) sessions stay online but are still suspended
+		setUserStatus(worker, &user, true, nil, suspensions)
+		
+		result = append(result, user)
 		addedUsers[session.Name] = true
 	}
 	
-	// B. Add Offline/Isolated Users from DB
+	// B. Add Offline/Suspended/Isolated Users from DB
 	for username, dbUser := range dbUsers {
 		if !addedUsers[username] {
-			status := "offline"
-			if !dbUser.IsEnabled {
-				status = "isolated"
-			}
-			
-			result = append(result, models.UserWithStatus{
+			user := models.UserWithStatus{
 				Username: username,
-				Status:   status,
 				Profile:  dbUser.Profile,
 				IP:       dbUser.RemoteAddress,
-			})
+				Secret:   &dbUser.SecretDetails,
+			}
+			setUserStatus(worker, &user, false, &dbUser, suspensions)
+			
+			result = append(result, user)
 		}
 	}
 	
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, applyListQuery(result, query, userField))
 }
 
 
+func setUserStatus(worker *core.Worker, user *models.UserWithStatus, online bool, dbUser *database.DBUser, suspensions map[string]models.Suspension) {
+	var suspension *models.Suspension
+	if s, ok := suspensions[user.Username]; ok {
+		suspension = &s
+	}
+
+	status, iso := subscriberStatus(worker, user.Username, user.IP, online, dbUser, suspension)
+	user.Status = status
+	user.Suspension = suspension
+	if iso != nil {
+		user.IsolationList = iso.List
+		user.IsolationComment = iso.Comment
+	}
+}
+
 // GetRouterHealth godoc
 // @Summary      Get Router Health
 // @Description  Returns CPU, Memory, and Uptime
@@ -322,6 +469,34 @@ func GetRouterHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, worker.SystemResource)
 }
 
+// GetRouterPools godoc
+// @Summary      Get Router IP Pools
+// @Description  Returns size, used addresses and utilization of each /ip/pool on the router
+// @Tags         Monitoring
+// @Accept       json
+// @Produce      json
+// @Param        id   path   int  true  "Router ID"
+// @Success      200  {array}  models.IPPoolUsage
+// @Router       /router/{id}/pools [get]
+func GetRouterPools(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.Atoi(idStr)
+	worker := core.GlobalPool.GetWorker(id)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found"})
+		return
+	}
+
+	worker.Lock.RLock()
+	defer worker.Lock.RUnlock()
+
+	if worker.IPPools == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "No data yet", "next_retry": "60s"})
+		return
+	}
+	c.JSON(http.StatusOK, worker.IPPools)
+}
+
 func GetUserTraffic(c *gin.Context) {
 	routerIDStr := c.Param("id")
 	routerID, _ := strconv.Atoi(routerIDStr)
@@ -338,28 +513,26 @@ func GetUserTraffic(c *gin.Context) {
 		return
 	}
 
-	cmd := core.Command{
-		Type: core.CmdGetTraffic,
-		Payload: user,
-		Error: make(chan error),
-		Result: make(chan interface{}),
-	}
-	
-	select {
-	case worker.CmdChan <- cmd:
-		select {
-		case res := <-cmd.Result:
-			c.JSON(http.StatusOK, res)
-		case err := <-cmd.Error:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		case <-time.After(10 * time.Second):
-			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timeout waiting for router"})
-		}
-	case <-time.After(2 * time.Second):
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Router queue full or offline"})
+	res, err := worker.Execute(core.CmdGetTraffic, user, 10*time.Second)
+	if err != nil {
+		respondCommandError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, res)
 }
 
+// TriggerBackup godoc
+// @Summary      Backup Router
+// @Description  Creates a /system/backup file on the router. With async=true the backup is queued and
+// @Description  202 is returned with a job ID to poll at /jobs/{id}.
+// @Tags         Control
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int   true   "Router ID"
+// @Param        async  query     bool  false  "Run in the background"
+// @Success      200  {object}  map[string]string
+// @Success      202  {object}  map[string]interface{}
+// @Router       /router/{id}/backup [post]
 func TriggerBackup(c *gin.Context) {
 	idStr := c.Param("id")
 	id, _ := strconv.Atoi(idStr)
@@ -370,17 +543,18 @@ func TriggerBackup(c *gin.Context) {
 	}
 
 	filename := "netengine_backup_" + time.Now().Format("20060102_150405")
-	cmd := core.Command{
-		Type: core.CmdBackup,
-		Payload: filename,
-		Error: make(chan error),
-		Result: make(chan interface{}),
+	if wantsAsync(c) {
+		submitJob(c, "backup", worker, func(progress func(int)) (interface{}, error) {
+			if _, err := worker.Execute(core.CmdBackup, filename, core.JobTimeout); err != nil {
+				return nil, err
+			}
+			return gin.H{"file": filename + ".backup"}, nil
+		})
+		return
 	}
-	
-	worker.CmdChan <- cmd
-	err := <-cmd.Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	if _, err := worker.Execute(core.CmdBackup, filename, commandTimeout); err != nil {
+		respondCommandError(c, err)
 		return
 	}
 	
@@ -388,8 +562,8 @@ func TriggerBackup(c *gin.Context) {
 }
 
 func GetRouters(c *gin.Context) {
-	routers, err := database.GetAllRouters()
-	if err != nil {
+	routers, err := core.LoadRouters(store)
+	if err != nil && routers == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch routers"})
 		return
 	}
@@ -402,3 +576,34 @@ func GetRouters(c *gin.Context) {
 	c.JSON(http.StatusOK, routers)
 }
 
+// commandTimeout bounds how long a handler waits for a worker to accept and finish a command
+const commandTimeout = 30 * time.Second
+
+// respondCommandError maps worker errors to HTTP status codes
+func respondCommandError(c *gin.Context, err error) {
+	switch err {
+	case core.ErrWorkerBusy:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Router queue full or offline"})
+	case core.ErrTimeout:
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Timeout waiting for router"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// changed extracts the "changed" flag of a state-checked command result
+func changed(res interface{}) bool {
+	switch r := res.(type) {
+	case core.Change:
+		return r.Changed
+	case map[string]interface{}:
+		c, _ := r["changed"].(bool)
+		return c
+	}
+	return false
+}
+
+// resolveWorker picks the router a request targets (see core.Pool.ResolveWorker)
+func resolveWorker(routerID int, username string) *core.Worker {
+	return core.GlobalPool.ResolveWorker(routerID, username)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"skynet-net-engine-api/internal/config"
 	
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -33,3 +35,26 @@ func TestHealthCheck(t *testing.T) {
 	expected := `{"muscle":"alive","status":"ok"}`
 	assert.JSONEq(t, expected, w.Body.String())
 }
+
+func TestGetConfigRedactsSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.Database.DSN = "engine:hunter2@tcp(db:3306)/netengine"
+	cfg.Database.SnapshotKey = "passphrase"
+	Configure(cfg)
+	t.Cleanup(func() { Configure(config.Default()) })
+
+	r := gin.New()
+	r.GET("/config", GetConfig)
+	req, _ := http.NewRequest("GET", "/config", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.NotContains(t, body, "hunter2")
+	assert.NotContains(t, body, "passphrase")
+	assert.NotContains(t, body, cfg.Server.AppKey)
+	assert.Contains(t, body, `"metrics_interval":"10s"`)
+	assert.Contains(t, body, `engine:********@tcp(db:3306)`)
+}
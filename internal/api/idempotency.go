@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyHeader lets clients retry POST/PUT requests safely
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyTTL is how long responses are kept for replay (server.idempotency_ttl)
+var IdempotencyTTL = time.Duration(defaults.Server.IdempotencyTTL)
+
+type idempotentResponse struct {
+	done        chan struct{} // Closed once the first request finished
+	fingerprint [32]byte      // Method, path and body of the first request
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// IdempotencyStore keeps responses by Idempotency-Key in memory
+type IdempotencyStore struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	responses map[string]*idempotentResponse
+	sweptAt   time.Time
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{ttl: ttl, responses: make(map[string]*idempotentResponse)}
+}
+
+// recordingWriter copies the response body while it is written
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware replays the stored response for a repeated Idempotency-Key on
+// POST and PUT requests. A duplicate arriving while the first request is
+// still running waits for it instead of running the handler again. Reusing a
+// key for a different request is rejected with 422. Server errors (5xx) are
+// not stored so that a retry gets another chance, except 504: the command
+// reached the router and may still have been applied, so running it again
+// is not safe.
+func (s *IdempotencyStore) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPut) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.String()+"\n"), body...))
+
+		for {
+			s.mu.Lock()
+			s.sweep()
+			prev, exists := s.responses[key]
+			if exists && prev.status != 0 && time.Now().After(prev.expires) {
+				exists = false
+			}
+			if !exists {
+				entry := &idempotentResponse{done: make(chan struct{}), fingerprint: fingerprint}
+				s.responses[key] = entry
+				s.mu.Unlock()
+				s.run(c, key, entry)
+				return
+			}
+			s.mu.Unlock()
+
+			if prev.fingerprint != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+				return
+			}
+
+			select {
+			case <-prev.done:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+			if prev.status == 0 {
+				continue // First attempt failed and was dropped; run this one
+			}
+
+			c.Header("Content-Type", prev.contentType)
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(prev.status, prev.contentType, prev.body)
+			c.Abort()
+			return
+		}
+	}
+}
+
+// run executes the handler chain for the first request with a key. Nothing is
+// stored when a handler panics or writes no response; the panic is passed on.
+func (s *IdempotencyStore) run(c *gin.Context, key string, entry *idempotentResponse) {
+	rec := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = rec
+
+	defer func() {
+		panicked := recover()
+		s.mu.Lock()
+		status := rec.Status()
+		if panicked != nil || !rec.Written() || (status >= http.StatusInternalServerError && status != http.StatusGatewayTimeout) {
+			delete(s.responses, key)
+		} else {
+			entry.status = status
+			entry.contentType = rec.Header().Get("Content-Type")
+			entry.body = rec.body.Bytes()
+			entry.expires = time.Now().Add(s.ttl)
+		}
+		s.mu.Unlock()
+		close(entry.done)
+		if panicked != nil {
+			panic(panicked)
+		}
+	}()
+
+	c.Next()
+}
+
+// sweep drops expired responses at most once a minute. Callers hold mu.
+func (s *IdempotencyStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.sweptAt) < time.Minute {
+		return
+	}
+	s.sweptAt = now
+	for key, r := range s.responses {
+		if r.status != 0 && now.After(r.expires) {
+			delete(s.responses, key)
+		}
+	}
+}
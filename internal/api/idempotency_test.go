@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotentRouter(calls *int32, status int, delay time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(NewIdempotencyStore(time.Hour).Middleware())
+	r.POST("/secret", func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+		c.JSON(status, gin.H{"call": n})
+	})
+	return r
+}
+
+func postWithKey(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/secret", strings.NewReader(body))
+	req.Header.Set(IdempotencyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls int32
+	r := newIdempotentRouter(&calls, http.StatusCreated, 0)
+
+	first := postWithKey(r, "k1", `{"user":"alice"}`)
+	second := postWithKey(r, "k1", `{"user":"alice"}`)
+
+	assert.Equal(t, int32(1), calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+
+	// Same key, different request
+	assert.Equal(t, http.StatusUnprocessableEntity, postWithKey(r, "k1", `{"user":"bob"}`).Code)
+
+	// No key, no replay
+	postWithKey(r, "", `{"user":"alice"}`)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestIdempotencyCoalescesInFlight(t *testing.T) {
+	var calls int32
+	r := newIdempotentRouter(&calls, http.StatusOK, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postWithKey(r, "k2", `{}`).Code
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
+
+func TestIdempotencyServerErrors(t *testing.T) {
+	for status, wantCalls := range map[int]int32{
+		http.StatusInternalServerError: 2, // Failed, safe to retry
+		http.StatusServiceUnavailable:  2, // Never reached the router
+		http.StatusGatewayTimeout:      1, // May have been applied
+	} {
+		var calls int32
+		r := newIdempotentRouter(&calls, status, 0)
+
+		postWithKey(r, "k3", `{}`)
+		w := postWithKey(r, "k3", `{}`)
+		assert.Equal(t, wantCalls, calls, status)
+		assert.Equal(t, status, w.Code)
+	}
+}
+
+func TestIdempotencyPanic(t *testing.T) {
+	var calls int32
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	r.Use(NewIdempotencyStore(time.Hour).Middleware())
+	r.POST("/secret", func(c *gin.Context) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, postWithKey(r, "k4", `{}`).Code)
+	second := postWithKey(r, "k4", `{}`)
+	assert.Equal(t, http.StatusCreated, second.Code, "the panic was not stored as an empty 200")
+	assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(2), calls)
+}
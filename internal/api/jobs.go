@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"skynet-net-engine-api/internal/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wantsAsync reports whether the caller asked for a background job (?async=true)
+func wantsAsync(c *gin.Context) bool {
+	async, _ := strconv.ParseBool(c.Query("async"))
+	return async
+}
+
+// submitJob queues run as an async job and answers 202 with where to poll it
+func submitJob(c *gin.Context, jobType string, worker *core.Worker, run core.JobFunc) {
+	job, err := core.SubmitJob(jobType, worker, run)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue job"})
+		return
+	}
+
+	location := "/api/v1/jobs/" + job.ID
+	c.Header("Location", location)
+	c.JSON(http.StatusAccepted, gin.H{"status": job.Status, "job_id": job.ID, "location": location})
+}
+
+// GetJob godoc
+// @Summary      Get Job
+// @Description  Returns the status, progress and result of an async job
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        id   path  string  true  "Job ID"
+// @Success      200  {object}  models.Job
+// @Router       /jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	job, err := store.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// GetJobs godoc
+// @Summary      List Jobs
+// @Description  Returns the most recent async jobs
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        status  query  string  false  "queued, running, succeeded or failed"
+// @Param        limit   query  int     false  "Max jobs (default 100, max 1000)"
+// @Success      200  {array}  models.Job
+// @Router       /jobs [get]
+func GetJobs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	jobs, err := store.GetJobs(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncRouterAsync(t *testing.T) {
+	useTestStore(t)
+	useTestPool(t, models.Router{ID: 1, Name: "core"})
+
+	// Stand in for the router: answer the sync queued by the job
+	worker := core.GlobalPool.GetWorker(1)
+	worker.IsOnline = true
+	go func() {
+		cmd := <-worker.CmdChan
+		cmd.Progress(50)
+		cmd.Result <- models.SyncSummary{Total: 3, Created: 3}
+		cmd.Error <- nil
+	}()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/sync/:id", SyncRouter)
+	r.GET("/jobs/:id", GetJob)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/sync/1?async=true", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var accepted struct {
+		Status   string `json:"status"`
+		JobID    string `json:"job_id"`
+		Location string `json:"location"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, models.JobQueued, accepted.Status)
+	assert.Equal(t, "/api/v1/jobs/"+accepted.JobID, accepted.Location)
+	assert.Equal(t, accepted.Location, w.Header().Get("Location"))
+
+	var job models.Job
+	require.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/"+accepted.JobID, nil)
+		r.ServeHTTP(w, req)
+		return w.Code == http.StatusOK && json.Unmarshal(w.Body.Bytes(), &job) == nil && job.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.JobSucceeded, job.Status)
+	assert.Equal(t, "sync", job.Type)
+	assert.Equal(t, 100, job.Progress)
+	assert.EqualValues(t, 3, job.Result.(map[string]interface{})["created"])
+}
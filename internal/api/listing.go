@@ -0,0 +1,279 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"skynet-net-engine-api/internal/mikrotik"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// listQuery holds the filter, sort and paging parameters shared by list endpoints
+type listQuery struct {
+	Status   string
+	Profile  string
+	Prefix   string // Username prefix
+	Search   string // Case-insensitive substring of username, IP or secret comment
+	Network  *net.IPNet
+	RouterID int
+	Sort     string // Field name, "-" prefix for descending
+	Limit    int
+	Offset   int // Decoded from the cursor
+}
+
+// Page is the envelope returned by paginated list endpoints
+type Page[T any] struct {
+	Total      int    `json:"total"` // Items matching the filters, across all pages
+	Count      int    `json:"count"` // Items in this page
+	NextCursor string `json:"next_cursor,omitempty"`
+	Data       []T    `json:"data"`
+}
+
+// queryRouterID reads the optional router_id filter; 0 means every router
+func queryRouterID(c *gin.Context) (int, error) {
+	v := c.Query("router_id")
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid router_id %q", v)
+	}
+	return id, nil
+}
+
+// queryLimit reads the optional page size (default defaultPageSize, capped at maxPageSize)
+func queryLimit(c *gin.Context) (int, error) {
+	v := c.Query("limit")
+	if v == "" {
+		return defaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("invalid limit %q", v)
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return limit, nil
+}
+
+// parseListQuery reads status, profile, prefix, q, cidr, router_id, sort, limit and cursor
+func parseListQuery(c *gin.Context) (listQuery, error) {
+	q := listQuery{
+		Status:  c.Query("status"),
+		Profile: c.Query("profile"),
+		Prefix:  c.Query("prefix"),
+		Search:  strings.ToLower(c.Query("q")),
+		Sort:    c.DefaultQuery("sort", "username"),
+		Limit:   defaultPageSize,
+	}
+
+	if cidr := c.Query("cidr"); cidr != "" {
+		// Accept a bare IP as a /32 (or /128)
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return q, fmt.Errorf("invalid cidr %q", c.Query("cidr"))
+		}
+		q.Network = network
+	}
+
+	var err error
+	if q.RouterID, err = queryRouterID(c); err != nil {
+		return q, err
+	}
+	if q.Limit, err = queryLimit(c); err != nil {
+		return q, err
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		offset, err := decodeCursor(v)
+		if err != nil {
+			return q, fmt.Errorf("invalid cursor")
+		}
+		q.Offset = offset
+	}
+
+	field := strings.TrimPrefix(q.Sort, "-")
+	if !sortableFields[field] {
+		return q, fmt.Errorf("cannot sort by %q", field)
+	}
+
+	return q, nil
+}
+
+var sortableFields = map[string]bool{
+	"username": true, "status": true, "profile": true, "ip": true,
+	"uptime": true, "router_id": true, "caller_id": true, "comment": true,
+}
+
+// Cursors are opaque to clients; they currently encode an offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "o:") {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o:"))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	return offset, nil
+}
+
+// fieldGetter exposes a list item's fields by their query parameter name
+type fieldGetter[T any] func(item T, field string) string
+
+func userField(u models.UserWithStatus, field string) string {
+	switch field {
+	case "username":
+		return u.Username
+	case "status":
+		return u.Status
+	case "profile":
+		return u.Profile
+	case "ip":
+		return u.IP
+	case "uptime":
+		return u.Uptime
+	case "comment":
+		if u.Secret != nil {
+			return u.Secret.Comment
+		}
+	}
+	return ""
+}
+
+func targetField(u models.ActiveUser, field string) string {
+	switch field {
+	case "username":
+		return u.Name
+	case "status":
+		return "connected"
+	case "ip":
+		return u.Address
+	case "uptime":
+		return u.Uptime
+	case "router_id":
+		return strconv.Itoa(u.RouterID)
+	case "caller_id":
+		return u.CallerID
+	}
+	return ""
+}
+
+// applyListQuery filters, sorts and slices items into a page
+func applyListQuery[T any](items []T, q listQuery, get fieldGetter[T]) Page[T] {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if matchesQuery(item, q, get) {
+			matched = append(matched, item)
+		}
+	}
+
+	field := strings.TrimPrefix(q.Sort, "-")
+	desc := strings.HasPrefix(q.Sort, "-")
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := get(matched[i], field), get(matched[j], field)
+		if a == b {
+			// Tie-break on username so pages are stable between requests
+			return get(matched[i], "username") < get(matched[j], "username")
+		}
+		if desc {
+			return compareField(field, b, a) < 0
+		}
+		return compareField(field, a, b) < 0
+	})
+
+	page := Page[T]{Total: len(matched), Data: []T{}}
+	if q.Offset < len(matched) {
+		end := q.Offset + q.Limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Data = matched[q.Offset:end]
+		if end < len(matched) {
+			page.NextCursor = encodeCursor(end)
+		}
+	}
+	page.Count = len(page.Data)
+	return page
+}
+
+func matchesQuery[T any](item T, q listQuery, get fieldGetter[T]) bool {
+	if q.Status != "" && get(item, "status") != q.Status {
+		return false
+	}
+	if q.Profile != "" && get(item, "profile") != q.Profile {
+		return false
+	}
+	if q.RouterID != 0 && get(item, "router_id") != strconv.Itoa(q.RouterID) {
+		return false
+	}
+	username := get(item, "username")
+	if q.Prefix != "" && !strings.HasPrefix(username, q.Prefix) {
+		return false
+	}
+	ip := get(item, "ip")
+	if q.Search != "" && !strings.Contains(strings.ToLower(username), q.Search) && !strings.Contains(ip, q.Search) &&
+		!strings.Contains(strings.ToLower(get(item, "comment")), q.Search) {
+		return false
+	}
+	if q.Network != nil {
+		addr := net.ParseIP(ip)
+		if addr == nil || !q.Network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareField orders values by their natural type rather than as strings
+func compareField(field, a, b string) int {
+	switch field {
+	case "uptime":
+		return compareInt(int64(mikrotik.ParseDuration(a)), int64(mikrotik.ParseDuration(b)))
+	case "router_id":
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return compareInt(x, y)
+	case "ip":
+		x, y := net.ParseIP(a), net.ParseIP(b)
+		if x != nil && y != nil {
+			return bytes.Compare(x.To16(), y.To16())
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
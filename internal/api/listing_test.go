@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func queryFor(t *testing.T, rawQuery string) listQuery {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/?"+rawQuery, nil)
+
+	q, err := parseListQuery(c)
+	assert.NoError(t, err)
+	return q
+}
+
+var testUsers = []models.UserWithStatus{
+	{Username: "budi", Status: "connected", IP: "10.10.0.9", Uptime: "1d2h", Profile: "10M"},
+	{Username: "andi", Status: "offline", IP: "10.20.0.1", Profile: "20M", Secret: &models.SecretDetails{Comment: "CUST-0042"}},
+	{Username: "bayu", Status: "connected", IP: "10.10.0.10", Uptime: "5m", Profile: "10M"},
+	{Username: "citra", Status: "isolated", IP: "10.10.1.4", Uptime: "3h", Profile: "10M"},
+}
+
+func TestApplyListQueryFilters(t *testing.T) {
+	page := applyListQuery(testUsers, queryFor(t, "status=connected&prefix=b"), userField)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "bayu", page.Data[0].Username)
+
+	page = applyListQuery(testUsers, queryFor(t, "cidr=10.10.0.0/24"), userField)
+	assert.Equal(t, 2, page.Total)
+
+	page = applyListQuery(testUsers, queryFor(t, "q=10.20"), userField)
+	assert.Equal(t, "andi", page.Data[0].Username)
+
+	// Customer IDs live in the secret comment
+	page = applyListQuery(testUsers, queryFor(t, "q=cust-0042"), userField)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, "andi", page.Data[0].Username)
+}
+
+func TestApplyListQuerySortsNaturally(t *testing.T) {
+	// 10.10.0.10 sorts after 10.10.0.9 as an address, not as a string
+	page := applyListQuery(testUsers, queryFor(t, "cidr=10.10.0.0/24&sort=ip"), userField)
+	assert.Equal(t, "budi", page.Data[0].Username)
+
+	page = applyListQuery(testUsers, queryFor(t, "status=connected&sort=-uptime"), userField)
+	assert.Equal(t, "budi", page.Data[0].Username)
+}
+
+func TestApplyListQueryPaginates(t *testing.T) {
+	first := applyListQuery(testUsers, queryFor(t, "limit=3"), userField)
+	assert.Equal(t, 4, first.Total)
+	assert.Equal(t, 3, first.Count)
+	assert.NotEmpty(t, first.NextCursor)
+
+	second := applyListQuery(testUsers, queryFor(t, "limit=3&cursor="+first.NextCursor), userField)
+	assert.Equal(t, 1, second.Count)
+	assert.Equal(t, "citra", second.Data[0].Username)
+	assert.Empty(t, second.NextCursor)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReconcileRouter godoc
+// @Summary      Reconcile Router Secrets
+// @Description  Makes the router's /ppp/secret list match the source of truth: pppoe_users ("db") or the users in the
+// @Description  request (e.g. from billing). mode "plan" only lists the changes, "apply" also performs them and "drift"
+// @Description  reports them with a router.drift webhook. Secrets missing from the source are ignored, disabled or
+// @Description  removed per "orphans". Suspended and FUP-throttled users are left alone. Creating a secret needs a
+// @Description  password, so creates from "db" are skipped. An apply that would remove or disable secrets because the
+// @Description  source is empty, or more than policy.reconcile_max_remove percent of them without "force", is refused
+// @Description  with 409. With async=true a job ID is returned instead.
+// @Tags         Reconciliation
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int               true   "Router ID"
+// @Param        async    query bool              false  "Run in the background"
+// @Param        request  body  ReconcileRequest  true   "Mode, source and orphan policy"
+// @Success      200  {object}  models.ReconcileResult
+// @Success      202  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /router/{id}/reconcile [post]
+func ReconcileRouter(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Router ID"})
+		return
+	}
+
+	var req ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = models.ReconcilePlan
+	}
+	if req.Source == "" {
+		req.Source = "db"
+	}
+	if req.Orphans == "" {
+		req.Orphans = core.OrphanIgnore
+	}
+
+	worker := core.GlobalPool.GetWorker(id)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found"})
+		return
+	}
+
+	opts := core.ReconcileOptions{Mode: req.Mode, Source: req.Source, Orphans: req.Orphans, Desired: req.Users, Force: req.Force}
+	if wantsAsync(c) {
+		submitJob(c, "reconcile", worker, func(progress func(int)) (interface{}, error) {
+			return worker.ExecuteWithProgress(core.CmdReconcile, opts, core.JobTimeout, progress)
+		})
+		return
+	}
+
+	res, err := worker.Execute(core.CmdReconcile, opts, core.JobTimeout)
+	if errors.Is(err, core.ErrUnsafeReconcile) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondCommandError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetReconcileResult godoc
+// @Summary      Last Reconciliation
+// @Description  Returns the result of the router's most recent reconciliation
+// @Tags         Reconciliation
+// @Accept       json
+// @Produce      json
+// @Param        id   path  int  true  "Router ID"
+// @Success      200  {object}  models.ReconcileResult
+// @Router       /router/{id}/reconcile [get]
+func GetReconcileResult(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Router ID"})
+		return
+	}
+
+	worker := core.GlobalPool.GetWorker(id)
+	if worker == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router not found"})
+		return
+	}
+
+	result := worker.LastReconcile()
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Router has not been reconciled yet"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"skynet-net-engine-api/internal/core"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAnomalies godoc
+// @Summary      Session Anomalies
+// @Description  Lists usernames online on several routers at once, MACs logged in as several usernames,
+// @Description  and recent MAC changes between sessions of the same username (possible account sharing)
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  core.AnomalyReport
+// @Router       /reports/anomalies [get]
+func GetAnomalies(c *gin.Context) {
+	c.JSON(http.StatusOK, core.GlobalPool.Anomalies.Report())
+}
+
+// GetIPConflicts godoc
+// @Summary      IP Address Conflicts
+// @Description  Cross-checks active session addresses and pppoe_users.remote_address fleet-wide for
+// @Description  static IPs pinned on several secrets, addresses held by several sessions, and sessions
+// @Description  holding another subscriber's static IP
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  models.IPConflict
+// @Router       /reports/ip-conflicts [get]
+func GetIPConflicts(c *gin.Context) {
+	conflicts, err := core.GlobalPool.IPConflicts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check static addresses"})
+		return
+	}
+	c.JSON(http.StatusOK, conflicts)
+}
+
+// GetIPPools godoc
+// @Summary      IP Pool Utilization
+// @Description  Returns /ip/pool utilization for every router (refreshed every minute). Pools at or
+// @Description  above POOL_ALERT_THRESHOLD percent fire a "pool.threshold_exceeded" webhook.
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  models.IPPoolUsage
+// @Router       /reports/ip-pools [get]
+func GetIPPools(c *gin.Context) {
+	c.JSON(http.StatusOK, core.GlobalPool.GetAllPools())
+}
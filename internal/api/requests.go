@@ -1,8 +1,55 @@
 package api
 
+import (
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+)
+
 type IsolateRequest struct {
-	IP     string `json:"ip" binding:"required"`
-	Action string `json:"action" binding:"required,oneof=add remove"` // add or remove
-	List   string `json:"list"` // Default to "ISOLATED" if empty
-	Comment string `json:"comment"`
+	IP       string `json:"ip" binding:"required_without=User"`
+	User     string `json:"user"` // Isolate by username; the entry follows the user's current IP
+	RouterID int    `json:"router_id"` // Optional; resolved from the user when omitted
+	Action   string `json:"action" binding:"required,oneof=add remove"` // add or remove
+	List     string `json:"list"` // Default to "ISOLATED" if empty
+	Comment  string `json:"comment"`
+}
+
+type KickRequest struct {
+	User     string `json:"user" binding:"required"`
+	RouterID int    `json:"router_id"` // Optional; resolved from the user when omitted
+}
+
+type SuspendRequest struct {
+	User     string `json:"user" binding:"required"`
+	RouterID int    `json:"router_id"`
+	Strategy string `json:"strategy" binding:"required,oneof=disable isolir"`
+	Profile  string `json:"profile"` // isolir strategy only; defaults to policy.suspend_profile
+	Comment  string `json:"comment"`
+}
+
+type ResumeRequest struct {
+	User     string `json:"user" binding:"required"`
+	RouterID int    `json:"router_id"`
+}
+
+type ScheduleRequest struct {
+	models.Operation
+	RunAt *time.Time `json:"run_at"` // One-shot, RFC3339
+	Cron  string     `json:"cron"`   // Recurring, 5-field cron in server local time
+}
+
+type BulkRequest struct {
+	Operations  []models.Operation `json:"operations" binding:"required,min=1,max=5000,dive"`
+	DryRun      bool               `json:"dry_run"`
+	StopOnError bool               `json:"stop_on_error"`
+	Concurrency int                `json:"concurrency"` // Default 10, max 50
+}
+
+type ReconcileRequest struct {
+	Mode    string                 `json:"mode" binding:"omitempty,oneof=plan apply drift"`           // Default "plan"
+	Source  string                 `json:"source" binding:"omitempty,oneof=db request"`               // Default "db"
+	Orphans string                 `json:"orphans" binding:"omitempty,oneof=ignore disable remove"`   // Default "ignore"
+	Users   []models.DesiredSecret `json:"users" binding:"required_if=Source request,omitempty,dive"` // Source "request" only
+	Force   bool                   `json:"force"`                                                     // Apply past policy.reconcile_max_remove
 }
@@ -1,17 +1,22 @@
 package api
 
 import (
-	"net/http"
-	"strings"
-	"io"
+	"crypto/subtle"
+
 	"github.com/gin-gonic/gin"
+	"skynet-net-engine-api/internal/database"
 	"skynet-net-engine-api/pkg/logger"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "skynet-net-engine-api/docs" // Import generated docs
 )
 
-func Start(port string) {
+// store is what the handlers read and write, set by Start
+var store database.Store
+
+// Start serves the API on port, with the handlers working against st
+func Start(port string, st database.Store) {
+	store = st
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	
@@ -19,9 +24,9 @@ func Start(port string) {
 	r.Use(gin.Recovery())
 	r.Use(func(c *gin.Context) {
 		// CORS for Dev
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
+		c.Writer.Header().Set("Access-Control-Allow-Origin", CORSOrigin)
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-App-Key")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-App-Key, Idempotency-Key")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -55,13 +60,13 @@ func Start(port string) {
 	secured := v1.Group("/")
 	secured.Use(func(c *gin.Context) {
 		key := c.GetHeader("X-App-Key")
-		// Hardcoded for MVP, move to ENV
-		if key != "netengine_secret_key_123" {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(AppKey)) != 1 {
 			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
 			return
 		}
 		c.Next()
 	})
+	secured.Use(NewIdempotencyStore(IdempotencyTTL).Middleware())
 	{
 		// Internal Control
 		secured.POST("/sync/:id", SyncRouter)
@@ -73,12 +78,52 @@ func Start(port string) {
 		
 		// Advanced
 		secured.POST("/isolate", IsolateUser)
+		secured.POST("/suspend", SuspendUser)
+		secured.POST("/resume", ResumeUser)
 		secured.GET("/routers", GetRouters)
 		secured.GET("/monitoring/targets", GetTargets)
 		secured.GET("/router/:id/health", GetRouterHealth)
 		secured.GET("/router/:id/users", GetAllUsers)
 		secured.GET("/router/:id/traffic", GetUserTraffic)
+		secured.GET("/router/:id/pools", GetRouterPools)
 		secured.POST("/router/:id/backup", TriggerBackup)
+		secured.POST("/router/:id/reconcile", ReconcileRouter)
+		secured.GET("/router/:id/reconcile", GetReconcileResult)
+		secured.GET("/router/:id/drift", GetRouterDrift)
+
+		// Subscribers (fleet-wide)
+		secured.GET("/subscribers", LookupSubscribers)
+		secured.GET("/subscribers/:username", GetSubscriber)
+		secured.GET("/subscribers/:username/sessions", GetSubscriberSessions)
+		secured.GET("/subscribers/:username/usage", GetSubscriberUsage)
+
+		// Fair usage policy
+		secured.GET("/fup/rules", GetFUPRules)
+		secured.POST("/fup/rules", SaveFUPRule)
+		secured.DELETE("/fup/rules/:id", DeleteFUPRule)
+		secured.GET("/fup/states", GetFUPStates)
+		secured.GET("/fup/events", GetFUPEvents)
+
+		// Async jobs
+		secured.GET("/jobs", GetJobs)
+		secured.GET("/jobs/:id", GetJob)
+
+		// Bulk
+		secured.POST("/bulk", RunBulk)
+
+		// Scheduler
+		secured.POST("/schedules", CreateSchedule)
+		secured.GET("/schedules", GetSchedules)
+		secured.GET("/schedules/:id", GetSchedule)
+		secured.DELETE("/schedules/:id", CancelSchedule)
+
+		// Reports
+		secured.GET("/reports/anomalies", GetAnomalies)
+		secured.GET("/reports/ip-conflicts", GetIPConflicts)
+		secured.GET("/reports/ip-pools", GetIPPools)
+
+		// System
+		secured.GET("/config", GetConfig)
 	}
 
 	logger.Info("Starting API Server on " + port)
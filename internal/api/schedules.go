@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSchedule godoc
+// @Summary      Schedule Operation
+// @Description  Schedules a profile change, isolation or kick once at run_at or repeatedly on a cron expression
+// @Description  (e.g. "0 22 * * *" for a night boost). Jobs whose router is offline are retried every minute.
+// @Tags         Scheduler
+// @Accept       json
+// @Produce      json
+// @Param        request body ScheduleRequest true "Operation and timing"
+// @Success      201  {object}  models.ScheduledJob
+// @Router       /schedules [post]
+func CreateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Action == models.OpCreateSecret {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "create_secret cannot be scheduled"})
+		return
+	}
+	if err := core.ValidateOperation(req.Operation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.RunAt == nil) == (req.Cron == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Exactly one of run_at or cron is required"})
+		return
+	}
+
+	now := time.Now()
+	next := req.RunAt
+	if req.Cron != "" {
+		cron, err := core.ParseCron(req.Cron)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		t := cron.Next(now)
+		if t.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cron schedule never fires"})
+			return
+		}
+		next = &t
+	}
+
+	job := models.ScheduledJob{
+		Operation: req.Operation,
+		Cron:      req.Cron,
+		RunAt:     req.RunAt,
+		NextRunAt: next,
+		Status:    models.SchedulePending,
+		CreatedAt: now,
+	}
+	id, err := store.CreateScheduledJob(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save scheduled job"})
+		return
+	}
+	job.ID = id
+
+	c.JSON(http.StatusCreated, job)
+}
+
+// GetSchedules godoc
+// @Summary      List Scheduled Jobs
+// @Description  Returns scheduled jobs, newest first
+// @Tags         Scheduler
+// @Accept       json
+// @Produce      json
+// @Param        status    query  string  false  "pending, done, failed or cancelled"
+// @Param        username  query  string  false  "Only jobs for this subscriber"
+// @Success      200  {array}  models.ScheduledJob
+// @Router       /schedules [get]
+func GetSchedules(c *gin.Context) {
+	jobs, err := store.GetScheduledJobs(c.Query("status"), c.Query("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetSchedule godoc
+// @Summary      Get Scheduled Job
+// @Description  Returns one scheduled job with its status, attempts and last error
+// @Tags         Scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id   path  int  true  "Job ID"
+// @Success      200  {object}  models.ScheduledJob
+// @Router       /schedules/{id} [get]
+func GetSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Job ID"})
+		return
+	}
+
+	job, err := store.GetScheduledJob(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled job"})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelSchedule godoc
+// @Summary      Cancel Scheduled Job
+// @Description  Cancels a pending job. Finished jobs cannot be cancelled.
+// @Tags         Scheduler
+// @Accept       json
+// @Produce      json
+// @Param        id   path  int  true  "Job ID"
+// @Success      200  {object}  map[string]string
+// @Router       /schedules/{id} [delete]
+func CancelSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Job ID"})
+		return
+	}
+
+	ok, err := store.CancelScheduledJob(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel scheduled job"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "Job not found or no longer pending"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "Job Cancelled", "id": id})
+}
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSubscriber godoc
+// @Summary      Find Subscriber
+// @Description  Searches every router for a username and returns its session, profile, IP, uptime,
+// @Description  isolation/suspension status and live traffic, one record per router it is on
+// @Tags         Subscribers
+// @Accept       json
+// @Produce      json
+// @Param        username  path   string  true   "PPPoE username"
+// @Param        traffic   query  bool    false  "Fetch live traffic for online sessions (default true)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Router       /subscribers/{username} [get]
+func GetSubscriber(c *gin.Context) {
+	username := c.Param("username")
+
+	sessions := core.GlobalPool.Index.ByName(username)
+	dbUsers, _ := store.FindUsers(username)
+	// DB errors only cost us offline records; live sessions still answer
+
+	records := buildSubscriberRecords(sessions, dbUsers, c.DefaultQuery("traffic", "true") != "false")
+	if len(records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subscriber not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"username": username, "records": records})
+}
+
+// LookupSubscribers godoc
+// @Summary      Find Subscriber by IP or MAC
+// @Description  Searches every router's sessions (and static addresses) by IP, or sessions and the
+// @Description  caller-id/last caller-id of stored secrets by MAC
+// @Tags         Subscribers
+// @Accept       json
+// @Produce      json
+// @Param        ip       query  string  false  "IP address"
+// @Param        mac      query  string  false  "MAC address / caller-id"
+// @Param        traffic  query  bool    false  "Fetch live traffic for online sessions (default true)"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /subscribers [get]
+func LookupSubscribers(c *gin.Context) {
+	ip, mac := c.Query("ip"), c.Query("mac")
+
+	var sessions []models.ActiveUser
+	var dbUsers []database.DBUser
+	switch {
+	case ip != "":
+		sessions = core.GlobalPool.Index.ByIP(ip)
+		dbUsers, _ = store.FindUsersByAddress(ip)
+	case mac != "":
+		sessions = core.GlobalPool.Index.ByMAC(mac)
+		dbUsers, _ = store.FindUsersByCallerID(core.NormalizeMAC(mac))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query param 'ip' or 'mac' required"})
+		return
+	}
+
+	records := buildSubscriberRecords(sessions, dbUsers, c.DefaultQuery("traffic", "true") != "false")
+	c.JSON(http.StatusOK, gin.H{"ip": ip, "mac": mac, "records": records})
+}
+
+// GetSubscriberSessions godoc
+// @Summary      Subscriber Connection Log
+// @Description  Returns the sessions of a username overlapping a time window, newest first, with
+// @Description  counts that help spot flapping lines
+// @Tags         Subscribers
+// @Accept       json
+// @Produce      json
+// @Param        username   path   string  true   "PPPoE username"
+// @Param        from       query  string  false  "Window start, RFC3339 or YYYY-MM-DD (default 7 days ago)"
+// @Param        to         query  string  false  "Window end, RFC3339 or YYYY-MM-DD (default now)"
+// @Param        router_id  query  int     false  "Only sessions on this router"
+// @Param        limit      query  int     false  "Max sessions (default 100, max 1000)"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /subscribers/{username}/sessions [get]
+func GetSubscriberSessions(c *gin.Context) {
+	username := c.Param("username")
+
+	now := time.Now()
+	from, err := parseTimeParam(c.Query("from"), now.AddDate(0, 0, -7))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from': " + err.Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to': " + err.Error()})
+		return
+	}
+	routerID, err := queryRouterID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := queryLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions, err := store.GetUserSessions(username, routerID, from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	// A disconnect is any session that ended inside the window
+	disconnects := 0
+	for _, s := range sessions {
+		if s.EndedAt != nil && s.EndedAt.Before(to) {
+			disconnects++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"username":    username,
+		"from":        from,
+		"to":          to,
+		"count":       len(sessions),
+		"disconnects": disconnects,
+		"sessions":    sessions,
+	})
+}
+
+// GetSubscriberUsage godoc
+// @Summary      Subscriber Data Usage
+// @Description  Returns bytes used per hour, day or month, collected from queue byte counters
+// @Tags         Subscribers
+// @Accept       json
+// @Produce      json
+// @Param        username     path   string  true   "PPPoE username"
+// @Param        granularity  query  string  false  "hourly, daily (default) or monthly"
+// @Param        from         query  string  false  "Window start, RFC3339 or YYYY-MM-DD (default 24h / 30d / 12 months ago)"
+// @Param        to           query  string  false  "Window end, RFC3339 or YYYY-MM-DD (default now)"
+// @Param        router_id    query  int     false  "Only usage on this router"
+// @Success      200  {object}  map[string]interface{}
+// @Router       /subscribers/{username}/usage [get]
+func GetSubscriberUsage(c *gin.Context) {
+	username := c.Param("username")
+	granularity := c.DefaultQuery("granularity", "daily")
+
+	now := time.Now()
+	var defaultFrom time.Time
+	switch granularity {
+	case "hourly":
+		defaultFrom = now.Add(-24 * time.Hour).Truncate(time.Hour)
+	case "daily":
+		defaultFrom = startOfDay(now.AddDate(0, 0, -29))
+	case "monthly":
+		defaultFrom = startOfMonth(now.AddDate(0, -11, 0))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be hourly, daily or monthly"})
+		return
+	}
+
+	from, err := parseTimeParam(c.Query("from"), defaultFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from': " + err.Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to': " + err.Error()})
+		return
+	}
+	routerID, err := queryRouterID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hourly, err := store.GetHourlyUsage(username, routerID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	points := aggregateUsage(hourly, granularity)
+	var totalRX, totalTX int64
+	for _, p := range points {
+		totalRX += p.RX
+		totalTX += p.TX
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"username":       username,
+		"granularity":    granularity,
+		"from":           from,
+		"to":             to,
+		"total_rx_bytes": totalRX,
+		"total_tx_bytes": totalTX,
+		"usage":          points,
+	})
+}
+
+// aggregateUsage folds hourly rows (oldest first) into days or months in local time
+func aggregateUsage(hourly []models.UsagePoint, granularity string) []models.UsagePoint {
+	if granularity == "hourly" {
+		return hourly
+	}
+
+	bucket := startOfDay
+	if granularity == "monthly" {
+		bucket = startOfMonth
+	}
+
+	points := make([]models.UsagePoint, 0)
+	for _, h := range hourly {
+		period := bucket(h.Period.Local())
+		if n := len(points); n > 0 && points[n-1].Period.Equal(period) {
+			points[n-1].RX += h.RX
+			points[n-1].TX += h.TX
+			continue
+		}
+		points = append(points, models.UsagePoint{Period: period, RX: h.RX, TX: h.TX})
+	}
+	return points
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// parseTimeParam accepts RFC3339 timestamps or plain dates (local midnight)
+func parseTimeParam(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// buildSubscriberRecords merges live sessions and DB rows into one record per username and router
+func buildSubscriberRecords(sessions []models.ActiveUser, dbUsers []database.DBUser, withTraffic bool) []models.SubscriberRecord {
+	records := make(map[string]*models.SubscriberRecord)
+	key := func(username string, routerID int) string {
+		return username + "|" + strconv.Itoa(routerID)
+	}
+
+	for _, s := range sessions {
+		records[key(s.Name, s.RouterID)] = &models.SubscriberRecord{
+			Username: s.Name,
+			RouterID: s.RouterID,
+			Session:  "online",
+			IP:       s.Address,
+			CallerID: s.CallerID,
+			Uptime:   s.Uptime,
+		}
+	}
+
+	provisioned := make(map[string]database.DBUser)
+	for _, u := range dbUsers {
+		k := key(u.Username, u.RouterID)
+		provisioned[k] = u
+		r, ok := records[k]
+		if !ok {
+			r = &models.SubscriberRecord{Username: u.Username, RouterID: u.RouterID, Session: "offline", IP: u.RemoteAddress}
+			records[k] = r
+		}
+		r.Provisioned = true
+		r.Profile = u.Profile
+		r.Secret = &u.SecretDetails
+	}
+
+	// One query per router rather than per record
+	suspensions := make(map[int]map[string]models.Suspension)
+	for _, r := range records {
+		if _, ok := suspensions[r.RouterID]; !ok {
+			suspensions[r.RouterID], _ = store.GetSuspensionsByRouter(r.RouterID)
+		}
+	}
+
+	result := make([]models.SubscriberRecord, 0, len(records))
+	for k, r := range records {
+		worker := core.GlobalPool.GetWorker(r.RouterID)
+		if worker != nil {
+			r.RouterName = worker.Router.Name
+		}
+
+		var dbUser *database.DBUser
+		if u, ok := provisioned[k]; ok {
+			dbUser = &u
+		}
+		var suspension *models.Suspension
+		if s, ok := suspensions[r.RouterID][r.Username]; ok {
+			suspension = &s
+		}
+		r.Status, r.Isolation = subscriberStatus(worker, r.Username, r.IP, r.Session == "online", dbUser, suspension)
+		r.Suspension = suspension
+
+		result = append(result, *r)
+	}
+
+	if withTraffic {
+		fetchSubscriberTraffic(result)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Username != result[j].Username {
+			return result[i].Username < result[j].Username
+		}
+		return result[i].RouterID < result[j].RouterID
+	})
+	return result
+}
+
+// subscriberTrafficConcurrency caps the traffic lookups in flight for one request
+const subscriberTrafficConcurrency = 8
+
+// fetchSubscriberTraffic fills in live traffic for online records, a few at a
+// time so a long result does not wait for each router in turn
+func fetchSubscriberTraffic(records []models.SubscriberRecord) {
+	sem := make(chan struct{}, subscriberTrafficConcurrency)
+	var wg sync.WaitGroup
+	for i := range records {
+		r := &records[i]
+		worker := core.GlobalPool.GetWorker(r.RouterID)
+		if worker == nil || r.Session != "online" {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if res, err := worker.Execute(core.CmdGetTraffic, r.Username, 3*time.Second); err == nil {
+				r.Traffic, _ = res.(*models.TrafficStats)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// subscriberStatus applies the status precedence shared by every user view:
+// suspended, then isolated, then connected or offline.
+func subscriberStatus(worker *core.Worker, username, ip string, online bool, dbUser *database.DBUser, suspension *models.Suspension) (string, *models.AddressListEntry) {
+	if suspension != nil {
+		return "suspended", nil
+	}
+	// Disabled directly on the router, outside of /suspend
+	if !online && dbUser != nil && !dbUser.IsEnabled {
+		return "suspended", nil
+	}
+	if worker != nil {
+		if iso := worker.IsolationOf(username, ip); iso != nil {
+			return "isolated", iso
+		}
+	}
+	if online {
+		return "connected", nil
+	}
+	return "offline", nil
+}
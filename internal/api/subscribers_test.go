@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/core"
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestPool installs a pool with idle workers for the given routers, on the
+// store from useTestStore
+func useTestPool(t *testing.T, routers ...models.Router) {
+	t.Helper()
+	pool := &core.Pool{Workers: map[int]*core.Worker{}, Index: core.NewSubscriberIndex(), Store: store}
+	for _, r := range routers {
+		pool.Workers[r.ID] = core.NewWorker(r, store, nil)
+	}
+	prev := core.GlobalPool
+	core.GlobalPool = pool
+	t.Cleanup(func() { core.GlobalPool = prev })
+}
+
+func TestBuildSubscriberRecords(t *testing.T) {
+	st := useTestStore(t)
+	useTestPool(t, models.Router{ID: 1, Name: "core"}, models.Router{ID: 2, Name: "edge"})
+	require.NoError(t, st.SaveSuspension(models.Suspension{
+		Username: "alice", RouterID: 2, Strategy: models.SuspendDisable, PreviousProfile: "10M", CreatedAt: time.Now(),
+	}))
+
+	sessions := []models.ActiveUser{{Name: "alice", Address: "10.0.0.5", Uptime: "1h", RouterID: 1}}
+	dbUsers := []database.DBUser{
+		{Username: "alice", RouterID: 1, Profile: "10M", IsEnabled: true},
+		{Username: "alice", RouterID: 2, Profile: "10M", RemoteAddress: "10.1.0.5", IsEnabled: false},
+		{Username: "alice", RouterID: 3, Profile: "20M", IsEnabled: true},
+	}
+
+	records := buildSubscriberRecords(sessions, dbUsers, false)
+	require.Len(t, records, 3)
+
+	// Live session merged with its row
+	assert.Equal(t, "online", records[0].Session)
+	assert.Equal(t, "connected", records[0].Status)
+	assert.Equal(t, "core", records[0].RouterName)
+	assert.Equal(t, "10.0.0.5", records[0].IP)
+	assert.Equal(t, "10M", records[0].Profile)
+	assert.True(t, records[0].Provisioned)
+
+	// Offline row with a suspension
+	assert.Equal(t, "offline", records[1].Session)
+	assert.Equal(t, "suspended", records[1].Status)
+	require.NotNil(t, records[1].Suspension)
+	assert.Equal(t, "10.1.0.5", records[1].IP)
+
+	// Router that is not in the pool
+	assert.Equal(t, "offline", records[2].Status)
+	assert.Empty(t, records[2].RouterName)
+}
+
+func TestLookupSubscribersByMAC(t *testing.T) {
+	st := useTestStore(t)
+	useTestPool(t, models.Router{ID: 1, Name: "core"})
+	_, err := st.SyncUsers(1, []models.PPPoESecret{
+		{Name: "alice", Profile: "10M", SecretDetails: models.SecretDetails{CallerID: "aa:bb:cc:dd:ee:01"}},
+		{Name: "bob", Profile: "10M", SecretDetails: models.SecretDetails{LastCallerID: "AA-BB-CC-DD-EE-02"}},
+		{Name: "carol", Profile: "10M"},
+	}, models.SyncDeleteMark)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/subscribers", LookupSubscribers)
+
+	// Neither is online: the stored caller-ids still find them
+	for mac, want := range map[string]string{"AA:BB:CC:DD:EE:01": "alice", "aa-bb-cc-dd-ee-02": "bob"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/subscribers?traffic=false&mac="+mac, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Records []models.SubscriberRecord `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Records, 1, mac)
+		assert.Equal(t, want, body.Records[0].Username)
+		assert.Equal(t, "offline", body.Records[0].Session)
+	}
+}
+
+func TestGetSubscriberSessionsRejectsBadParams(t *testing.T) {
+	useTestStore(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/subscribers/:username/sessions", GetSubscriberSessions)
+
+	for query, want := range map[string]int{
+		"":                     http.StatusOK,
+		"?limit=5&router_id=1": http.StatusOK,
+		"?limit=0":             http.StatusBadRequest,
+		"?limit=abc":           http.StatusBadRequest,
+		"?router_id=core":      http.StatusBadRequest,
+		"?from=yesterday":      http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/subscribers/alice/sessions"+query, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, query)
+	}
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is every engine setting. It is built from Default, then the optional
+// CONFIG_FILE (YAML or JSON), then environment variables (the env tag of each
+// field). Empty environment variables count as unset.
+type Config struct {
+	Server   Server   `yaml:"server" json:"server"`
+	Database Database `yaml:"database" json:"database"`
+	Routers  Routers  `yaml:"routers" json:"routers"`
+	Workers  Workers  `yaml:"workers" json:"workers"`
+	Policy   Policy   `yaml:"policy" json:"policy"`
+	Webhook  Webhook  `yaml:"webhook" json:"webhook"`
+}
+
+// Server is the HTTP API
+type Server struct {
+	Port           string   `yaml:"port" json:"port" env:"API_PORT"` // ":8080", "8080" or "host:port"
+	CORSOrigin     string   `yaml:"cors_origin" json:"cors_origin" env:"CORS_ORIGIN"`
+	AppKey         string   `yaml:"app_key" json:"app_key" env:"APP_KEY"` // X-App-Key for /api/v1
+	IdempotencyTTL Duration `yaml:"idempotency_ttl" json:"idempotency_ttl" env:"IDEMPOTENCY_TTL" swaggertype:"string"`
+}
+
+// Database is the store and its offline snapshot
+type Database struct {
+	Driver       string `yaml:"driver" json:"driver" env:"DB_DRIVER"` // "mysql" or "sqlite"
+	DSN          string `yaml:"dsn" json:"dsn" env:"DB_DSN"`          // SQLite: file path, default netengine.db
+	AutoMigrate  bool   `yaml:"auto_migrate" json:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	SnapshotPath string `yaml:"snapshot_path" json:"snapshot_path" env:"SNAPSHOT_PATH"`
+	SnapshotKey  string `yaml:"snapshot_key" json:"snapshot_key" env:"SNAPSHOT_KEY"` // Snapshots are off without it
+}
+
+// Routers is the router inventory file
+type Routers struct {
+	File   string `yaml:"file" json:"file" env:"ROUTERS_FILE"`
+	Source string `yaml:"source" json:"source" env:"ROUTERS_SOURCE"` // "db", "file" or "merge" (default with a file)
+}
+
+// Workers are the per-router connection timings
+type Workers struct {
+	MetricsInterval Duration `yaml:"metrics_interval" json:"metrics_interval" env:"METRICS_INTERVAL" swaggertype:"string"`
+	WarmupTimeout   Duration `yaml:"warmup_timeout" json:"warmup_timeout" env:"WARMUP_TIMEOUT" swaggertype:"string"`    // How long startup waits for routers
+	ConnectRetry    Duration `yaml:"connect_retry" json:"connect_retry" env:"CONNECT_RETRY" swaggertype:"string"`       // Wait after a failed connect
+	ReconnectDelay  Duration `yaml:"reconnect_delay" json:"reconnect_delay" env:"RECONNECT_DELAY" swaggertype:"string"` // Wait after a dropped connection
+	CommandBuffer   int      `yaml:"command_buffer" json:"command_buffer" env:"WORKER_COMMAND_BUFFER"`                  // Queued commands per router
+}
+
+// Policy is how subscribers are handled
+type Policy struct {
+	IsolationLists         []string `yaml:"isolation_lists" json:"isolation_lists" env:"ISOLATION_LISTS"`
+	SuspendProfile         string   `yaml:"suspend_profile" json:"suspend_profile" env:"SUSPEND_PROFILE"`
+	PoolAlertThreshold     float64  `yaml:"pool_alert_threshold" json:"pool_alert_threshold" env:"POOL_ALERT_THRESHOLD"` // Percent
+	ReconcileMaxRemove     float64  `yaml:"reconcile_max_remove" json:"reconcile_max_remove" env:"RECONCILE_MAX_REMOVE"` // Percent of a router's secrets
+	FUPCycleDay            int      `yaml:"fup_cycle_day" json:"fup_cycle_day" env:"FUP_CYCLE_DAY"`
+	SyncDeletePolicy       string   `yaml:"sync_delete_policy" json:"sync_delete_policy" env:"SYNC_DELETE_POLICY"`
+	AllowMultiSessionUsers []string `yaml:"allow_multi_session_users" json:"allow_multi_session_users" env:"ALLOW_MULTI_SESSION_USERS"`
+	AllowMACChangeUsers    []string `yaml:"allow_mac_change_users" json:"allow_mac_change_users" env:"ALLOW_MAC_CHANGE_USERS"`
+	AllowSharedMACs        []string `yaml:"allow_shared_macs" json:"allow_shared_macs" env:"ALLOW_SHARED_MACS"`
+}
+
+// Webhook is where router and pool events are posted
+type Webhook struct {
+	URL string `yaml:"url" json:"url" env:"WEBHOOK_URL"` // Empty disables webhooks
+}
+
+// Duration is a time.Duration written as "10s" in files, env and JSON
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(time.Duration(d).String()), nil }
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// mysqlFallbackDSN is the development database used when DB_DSN is not set
+const mysqlFallbackDSN = "fairusinampratama@tcp(127.0.0.1:3306)/netengine?parseTime=true"
+
+// Default returns the settings used when nothing overrides them
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:           ":8080",
+			CORSOrigin:     "http://localhost:5173",
+			AppKey:         "netengine_secret_key_123",
+			IdempotencyTTL: Duration(24 * time.Hour),
+		},
+		Database: Database{
+			Driver:       "mysql",
+			AutoMigrate:  true,
+			SnapshotPath: "netengine.snapshot",
+		},
+		Workers: Workers{
+			MetricsInterval: Duration(10 * time.Second),
+			WarmupTimeout:   Duration(5 * time.Second),
+			ConnectRetry:    Duration(5 * time.Second),
+			ReconnectDelay:  Duration(3 * time.Second),
+			CommandBuffer:   10,
+		},
+		Policy: Policy{
+			IsolationLists:         []string{"ISOLATED"},
+			SuspendProfile:         "isolir",
+			PoolAlertThreshold:     90,
+			ReconcileMaxRemove:     10,
+			FUPCycleDay:            1,
+			SyncDeletePolicy:       models.SyncDeleteMark,
+			AllowMultiSessionUsers: []string{},
+			AllowMACChangeUsers:    []string{},
+			AllowSharedMACs:        []string{},
+		},
+		Webhook: Webhook{URL: "http://localhost:8000/api/webhooks/net-engine"},
+	}
+}
+
+// Load reads the file named by CONFIG_FILE (if any) and the environment
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile builds and validates the config from defaults, path (skipped when
+// empty) and the environment. Every invalid setting is reported at once.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// JSON is valid YAML, so one decoder covers both
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.DSN == "" && cfg.Database.Driver == "mysql" {
+		cfg.Database.DSN = mysqlFallbackDSN
+	}
+	if cfg.Routers.Source == "" {
+		cfg.Routers.Source = "db"
+		if cfg.Routers.File != "" {
+			cfg.Routers.Source = "merge"
+		}
+	}
+	if port := cfg.Server.Port; port != "" && !strings.Contains(port, ":") {
+		cfg.Server.Port = ":" + port
+	}
+	return cfg, cfg.Validate()
+}
+
+var textUnmarshaler = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+
+// applyEnv overrides fields that have an env tag with the variable's value.
+// Lists are comma separated, blanks skipped.
+func applyEnv(v reflect.Value) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field, def := v.Field(i), v.Type().Field(i)
+		if def.Type.Kind() == reflect.Struct && def.Tag.Get("env") == "" {
+			if err := applyEnv(field); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		name := def.Tag.Get("env")
+		value := strings.TrimSpace(os.Getenv(name))
+		if name == "" || value == "" {
+			continue
+		}
+
+		var err error
+		switch {
+		case field.Addr().Type().Implements(textUnmarshaler):
+			err = field.Addr().Interface().(interface{ UnmarshalText([]byte) error }).UnmarshalText([]byte(value))
+		case def.Type.Kind() == reflect.String:
+			field.SetString(value)
+		case def.Type.Kind() == reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(value)
+			field.SetBool(b)
+		case def.Type.Kind() == reflect.Int:
+			var n int64
+			n, err = strconv.ParseInt(value, 10, 0)
+			field.SetInt(n)
+		case def.Type.Kind() == reflect.Float64:
+			var f float64
+			f, err = strconv.ParseFloat(value, 64)
+			field.SetFloat(f)
+		case def.Type == reflect.TypeOf([]string(nil)):
+			list := make([]string, 0)
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			field.Set(reflect.ValueOf(list))
+		default:
+			err = fmt.Errorf("unsupported type %s", def.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks every setting and returns all problems joined
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, port, err := net.SplitHostPort(c.Server.Port)
+	n, _ := strconv.Atoi(port)
+	check(err == nil && n >= 1 && n <= 65535, "server.port (API_PORT): %q is not a valid listen address", c.Server.Port)
+	check(c.Server.AppKey != "", "server.app_key (APP_KEY) is required")
+	check(c.Server.IdempotencyTTL > 0, "server.idempotency_ttl (IDEMPOTENCY_TTL) must be positive")
+
+	check(c.Database.Driver == "mysql" || c.Database.Driver == "sqlite", "database.driver (DB_DRIVER): %q is not mysql or sqlite", c.Database.Driver)
+
+	switch c.Routers.Source {
+	case "db", "merge":
+	case "file":
+		check(c.Routers.File != "", "routers.source (ROUTERS_SOURCE) is file but no routers.file (ROUTERS_FILE) is set")
+	default:
+		check(false, "routers.source (ROUTERS_SOURCE): %q is not db, file or merge", c.Routers.Source)
+	}
+
+	for name, d := range map[string]Duration{
+		"workers.metrics_interval (METRICS_INTERVAL)": c.Workers.MetricsInterval,
+		"workers.warmup_timeout (WARMUP_TIMEOUT)":     c.Workers.WarmupTimeout,
+		"workers.connect_retry (CONNECT_RETRY)":       c.Workers.ConnectRetry,
+		"workers.reconnect_delay (RECONNECT_DELAY)":   c.Workers.ReconnectDelay,
+	} {
+		check(d > 0, "%s must be positive", name)
+	}
+	check(c.Workers.CommandBuffer >= 1, "workers.command_buffer (WORKER_COMMAND_BUFFER) must be at least 1")
+
+	check(len(c.Policy.IsolationLists) > 0, "policy.isolation_lists (ISOLATION_LISTS) needs at least one list")
+	check(c.Policy.SuspendProfile != "", "policy.suspend_profile (SUSPEND_PROFILE) is required")
+	check(c.Policy.PoolAlertThreshold > 0 && c.Policy.PoolAlertThreshold <= 100, "policy.pool_alert_threshold (POOL_ALERT_THRESHOLD) must be between 0 and 100")
+	check(c.Policy.ReconcileMaxRemove > 0 && c.Policy.ReconcileMaxRemove <= 100, "policy.reconcile_max_remove (RECONCILE_MAX_REMOVE) must be between 0 and 100")
+	check(c.Policy.FUPCycleDay >= 1 && c.Policy.FUPCycleDay <= 28, "policy.fup_cycle_day (FUP_CYCLE_DAY) must be between 1 and 28")
+	check(c.Policy.SyncDeletePolicy == models.SyncDeleteMark || c.Policy.SyncDeletePolicy == models.SyncDeleteRemove,
+		"policy.sync_delete_policy (SYNC_DELETE_POLICY): %q is not %s or %s", c.Policy.SyncDeletePolicy, models.SyncDeleteMark, models.SyncDeleteRemove)
+
+	if c.Webhook.URL != "" {
+		u, err := url.Parse(c.Webhook.URL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "webhook.url (WEBHOOK_URL): %q is not an http(s) URL", c.Webhook.URL)
+	}
+	return errors.Join(errs...)
+}
+
+const redacted = "********"
+
+// dsnPassword matches the password the way the MySQL driver splits a DSN: it
+// runs up to the last @ before the network part, so it may itself contain @
+var dsnPassword = regexp.MustCompile(`^([^:@/]*):(.*)@([^@]*/[^/]*)$`)
+
+// Redacted returns a copy that is safe to show: keys are masked and so is the
+// password inside the DSN
+func (c *Config) Redacted() *Config {
+	out := *c
+	for _, secret := range []*string{&out.Server.AppKey, &out.Database.SnapshotKey} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	out.Database.DSN = dsnPassword.ReplaceAllString(out.Database.DSN, "$1:"+redacted+"@$3")
+	if u, err := url.Parse(out.Webhook.URL); err == nil && u.User != nil {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+		out.Webhook.URL = u.String()
+	}
+	return &out
+}
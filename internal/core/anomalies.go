@@ -0,0 +1,181 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type AnomalyType string
+
+const (
+	AnomalyDuplicateSession AnomalyType = "duplicate_session" // Same username online on several routers
+	AnomalyMACChanged       AnomalyType = "mac_changed"       // Username reconnected from a different MAC
+	AnomalySharedMAC        AnomalyType = "shared_mac"        // One MAC online as several usernames
+)
+
+// maxMACChanges bounds the MAC change history kept for the report
+const maxMACChanges = 500
+
+// Anomaly is a suspicious session pattern found across the fleet
+type Anomaly struct {
+	Type        AnomalyType         `json:"type"`
+	Username    string              `json:"username,omitempty"`
+	MAC         string              `json:"mac,omitempty"`
+	PreviousMAC string              `json:"previous_mac,omitempty"`
+	Sessions    []models.ActiveUser `json:"sessions"`
+	DetectedAt  time.Time           `json:"detected_at"`
+}
+
+// AnomalyReport is the current state of session anomaly detection
+type AnomalyReport struct {
+	DuplicateSessions []Anomaly `json:"duplicate_sessions"`
+	SharedMACs        []Anomaly `json:"shared_macs"`
+	MACChanges        []Anomaly `json:"mac_changes"` // Most recent first
+}
+
+// AnomalyDetector looks for account sharing and duplicate logins. Allow-lists
+// come from the policy.allow_* settings.
+type AnomalyDetector struct {
+	mu sync.RWMutex
+
+	allowMultiSession map[string]bool
+	allowMACChange    map[string]bool
+	allowSharedMAC    map[string]bool
+
+	lastMAC    map[string]string  // username -> caller-id of its latest session
+	duplicates map[string]Anomaly // username -> finding
+	shared     map[string]Anomaly // MAC -> finding
+	macChanges []Anomaly
+}
+
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{
+		allowMultiSession: toSet(AllowMultiSessionUsers, nil),
+		allowMACChange:    toSet(AllowMACChangeUsers, nil),
+		allowSharedMAC:    toSet(AllowSharedMACs, NormalizeMAC),
+		lastMAC:           make(map[string]string),
+		duplicates:        make(map[string]Anomaly),
+		shared:            make(map[string]Anomaly),
+		macChanges:        make([]Anomaly, 0),
+	}
+}
+
+// observe updates findings after a router's sessions changed
+func (d *AnomalyDetector) observe(p *Pool, events []SessionEvent) {
+	now := time.Now()
+	found := make([]Anomaly, 0)
+
+	d.mu.Lock()
+	// 1. MAC changes between sessions of the same username
+	for _, ev := range events {
+		if ev.Type != SessionConnected || ev.User.CallerID == "" {
+			continue
+		}
+		mac := NormalizeMAC(ev.User.CallerID)
+		prev, known := d.lastMAC[ev.User.Name]
+		d.lastMAC[ev.User.Name] = mac
+		if !known || prev == mac || d.allowMACChange[ev.User.Name] {
+			continue
+		}
+		a := Anomaly{Type: AnomalyMACChanged, Username: ev.User.Name, MAC: mac, PreviousMAC: prev, Sessions: []models.ActiveUser{ev.User}, DetectedAt: now}
+		d.macChanges = append([]Anomaly{a}, d.macChanges...)
+		if len(d.macChanges) > maxMACChanges {
+			d.macChanges = d.macChanges[:maxMACChanges]
+		}
+		found = append(found, a)
+	}
+
+	// 2. Duplicate sessions and shared MACs are re-evaluated from the index
+	byName, byMAC := p.Index.snapshot()
+
+	duplicates := make(map[string]Anomaly)
+	for name, sessions := range byName {
+		if d.allowMultiSession[name] || countRouters(sessions) < 2 {
+			continue
+		}
+		a, seen := d.duplicates[name]
+		if !seen {
+			a = Anomaly{Type: AnomalyDuplicateSession, Username: name, DetectedAt: now}
+			found = append(found, Anomaly{Type: a.Type, Username: name, Sessions: sessions, DetectedAt: now})
+		}
+		a.Sessions = sessions
+		duplicates[name] = a
+	}
+	d.duplicates = duplicates
+
+	shared := make(map[string]Anomaly)
+	for mac, sessions := range byMAC {
+		if d.allowSharedMAC[mac] || countUsernames(sessions) < 2 {
+			continue
+		}
+		a, seen := d.shared[mac]
+		if !seen {
+			a = Anomaly{Type: AnomalySharedMAC, MAC: mac, DetectedAt: now}
+			found = append(found, Anomaly{Type: a.Type, MAC: mac, Sessions: sessions, DetectedAt: now})
+		}
+		a.Sessions = sessions
+		shared[mac] = a
+	}
+	d.shared = shared
+	d.mu.Unlock()
+
+	// Only new findings are announced
+	for _, a := range found {
+		logger.Warn("Session anomaly detected", zap.String("type", string(a.Type)), zap.String("user", a.Username), zap.String("mac", a.MAC))
+		routerID, host := 0, ""
+		if len(a.Sessions) > 0 {
+			routerID = a.Sessions[len(a.Sessions)-1].RouterID
+			if w := p.GetWorker(routerID); w != nil {
+				host = w.Router.Host
+			}
+		}
+		SendWebhook("subscriber."+string(a.Type), routerID, host, a)
+	}
+}
+
+// Report returns the current findings, sorted for stable output
+func (d *AnomalyDetector) Report() AnomalyReport {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	report := AnomalyReport{
+		DuplicateSessions: make([]Anomaly, 0, len(d.duplicates)),
+		SharedMACs:        make([]Anomaly, 0, len(d.shared)),
+		MACChanges:        append([]Anomaly{}, d.macChanges...),
+	}
+	for _, a := range d.duplicates {
+		report.DuplicateSessions = append(report.DuplicateSessions, a)
+	}
+	for _, a := range d.shared {
+		report.SharedMACs = append(report.SharedMACs, a)
+	}
+	sort.Slice(report.DuplicateSessions, func(i, j int) bool {
+		return report.DuplicateSessions[i].Username < report.DuplicateSessions[j].Username
+	})
+	sort.Slice(report.SharedMACs, func(i, j int) bool {
+		return report.SharedMACs[i].MAC < report.SharedMACs[j].MAC
+	})
+	return report
+}
+
+func countRouters(sessions []models.ActiveUser) int {
+	routers := make(map[int]bool)
+	for _, s := range sessions {
+		routers[s.RouterID] = true
+	}
+	return len(routers)
+}
+
+func countUsernames(sessions []models.ActiveUser) int {
+	names := make(map[string]bool)
+	for _, s := range sessions {
+		names[s.Name] = true
+	}
+	return len(names)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	p := &Pool{Workers: map[int]*Worker{}, Index: NewSubscriberIndex(), Anomalies: NewAnomalyDetector()}
+	p.Anomalies.allowSharedMAC["FF:FF:FF:FF:FF:FF"] = true
+
+	observe := func(routerID int, users ...models.ActiveUser) {
+		p.Index.mu.RLock()
+		prev := p.Index.byRouter[routerID]
+		p.Index.mu.RUnlock()
+		p.Index.Update(routerID, users)
+		p.Anomalies.observe(p, diffSessions(prev, users))
+	}
+
+	// 1. Same username on two routers
+	observe(1, models.ActiveUser{Name: "andi", CallerID: "aa:aa:aa:aa:aa:01", RouterID: 1})
+	observe(2, models.ActiveUser{Name: "andi", CallerID: "aa:aa:aa:aa:aa:02", RouterID: 2})
+	report := p.Anomalies.Report()
+	assert.Len(t, report.DuplicateSessions, 1)
+	assert.Equal(t, "andi", report.DuplicateSessions[0].Username)
+	assert.Len(t, report.DuplicateSessions[0].Sessions, 2)
+
+	// The second login came from another MAC as well
+	assert.Len(t, report.MACChanges, 1)
+	assert.Equal(t, "AA:AA:AA:AA:AA:01", report.MACChanges[0].PreviousMAC)
+
+	// 2. One MAC as several usernames, unless allow-listed
+	observe(3,
+		models.ActiveUser{Name: "budi", CallerID: "BB:BB:BB:BB:BB:BB", RouterID: 3},
+		models.ActiveUser{Name: "bayu", CallerID: "bb-bb-bb-bb-bb-bb", RouterID: 3},
+		models.ActiveUser{Name: "cafe1", CallerID: "ff:ff:ff:ff:ff:ff", RouterID: 3},
+		models.ActiveUser{Name: "cafe2", CallerID: "ff:ff:ff:ff:ff:ff", RouterID: 3},
+	)
+	report = p.Anomalies.Report()
+	assert.Len(t, report.SharedMACs, 1)
+	assert.Equal(t, "BB:BB:BB:BB:BB:BB", report.SharedMACs[0].MAC)
+
+	// 3. Findings clear once the sessions are gone
+	observe(2)
+	observe(3)
+	report = p.Anomalies.Report()
+	assert.Empty(t, report.DuplicateSessions)
+	assert.Empty(t, report.SharedMACs)
+}
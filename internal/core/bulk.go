@@ -0,0 +1,114 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+const (
+	DefaultBulkConcurrency = 10
+	MaxBulkConcurrency     = 50
+)
+
+// BulkOptions controls how RunBulk fans operations out
+type BulkOptions struct {
+	DryRun      bool // Validate and resolve routers without changing anything
+	StopOnError bool // Skip the remaining operations after the first failure
+	Concurrency int  // Operations in flight at once (default 10, max 50)
+}
+
+// BulkSummary is the outcome of a bulk run
+type BulkSummary struct {
+	Total     int                      `json:"total"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Skipped   int                      `json:"skipped"`
+	DryRun    bool                     `json:"dry_run"`
+	Results   []models.OperationResult `json:"results"`
+}
+
+// RunBulk runs ops on their routers with bounded concurrency and returns a
+// result per item, in request order. Each router still executes its share
+// one command at a time through its worker.
+func (p *Pool) RunBulk(ops []models.Operation, opts BulkOptions, timeout time.Duration) BulkSummary {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBulkConcurrency
+	}
+	if opts.Concurrency > MaxBulkConcurrency {
+		opts.Concurrency = MaxBulkConcurrency
+	}
+
+	results := make([]models.OperationResult, len(ops))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stopped := false
+
+	for i, op := range ops {
+		results[i] = models.OperationResult{Index: i, Action: op.Action, User: op.User, RouterID: op.RouterID}
+
+		sem <- struct{}{}
+		mu.Lock()
+		halt := stopped
+		mu.Unlock()
+		if halt {
+			<-sem
+			results[i].Status = "skipped"
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, op models.Operation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := &results[i]
+			var w *Worker
+			var out interface{}
+			var err error
+			if opts.DryRun {
+				w, err = p.planOperation(op)
+			} else {
+				w, out, err = p.RunOperation(op, timeout)
+			}
+			if w != nil {
+				res.RouterID = w.Router.ID
+			}
+
+			if err != nil {
+				res.Status = "error"
+				res.Error = err.Error()
+				if opts.StopOnError {
+					mu.Lock()
+					stopped = true
+					mu.Unlock()
+				}
+				return
+			}
+			if opts.DryRun {
+				res.Status = "planned"
+				return
+			}
+			res.Status = "ok"
+			if out != "Success" {
+				res.Result = out
+			}
+		}(i, op)
+	}
+	wg.Wait()
+
+	summary := BulkSummary{Total: len(ops), DryRun: opts.DryRun, Results: results}
+	for _, r := range results {
+		switch r.Status {
+		case "ok", "planned":
+			summary.Succeeded++
+		case "error":
+			summary.Failed++
+		case "skipped":
+			summary.Skipped++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBulkDryRun(t *testing.T) {
+	p := &Pool{Workers: map[int]*Worker{
+		1: {Router: models.Router{ID: 1}, IsOnline: true},
+		2: {Router: models.Router{ID: 2}},
+	}}
+
+	ops := []models.Operation{
+		{Action: models.OpChangeProfile, User: "alice", RouterID: 1, Profile: "20M"},
+		{Action: models.OpChangeProfile, User: "bob", RouterID: 1}, // missing profile
+		{Action: models.OpKick, User: "carol", RouterID: 2},        // router offline
+		{Action: models.OpKick, User: "dave", RouterID: 9},         // unknown router
+	}
+
+	s := p.RunBulk(ops, BulkOptions{DryRun: true}, time.Second)
+	assert.Equal(t, 4, s.Total)
+	assert.Equal(t, 1, s.Succeeded)
+	assert.Equal(t, 3, s.Failed)
+	assert.Equal(t, "planned", s.Results[0].Status)
+	assert.Equal(t, "error", s.Results[1].Status)
+	assert.Equal(t, ErrRouterOffline.Error(), s.Results[2].Error)
+	assert.Equal(t, 2, s.Results[2].RouterID)
+	assert.Equal(t, ErrRouterNotFound.Error(), s.Results[3].Error)
+}
+
+func TestRunBulkStopOnError(t *testing.T) {
+	p := &Pool{Workers: map[int]*Worker{1: {Router: models.Router{ID: 1}, IsOnline: true}}}
+
+	ops := []models.Operation{
+		{Action: models.OpKick, User: "alice", RouterID: 1},
+		{Action: models.OpKick, User: "bob", RouterID: 9},
+		{Action: models.OpKick, User: "carol", RouterID: 1},
+		{Action: models.OpKick, User: "dave", RouterID: 1},
+	}
+
+	s := p.RunBulk(ops, BulkOptions{DryRun: true, StopOnError: true, Concurrency: 1}, time.Second)
+	assert.Equal(t, 1, s.Succeeded)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, 2, s.Skipped)
+	assert.Equal(t, "skipped", s.Results[3].Status)
+}
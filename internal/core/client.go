@@ -0,0 +1,29 @@
+package core
+
+import "skynet-net-engine-api/internal/models"
+
+// RouterClient is what a worker needs from a router connection.
+// *mikrotik.Client implements it; tests use a fake.
+type RouterClient interface {
+	Close()
+
+	GetSecret(user string) (*models.PPPoESecret, error)
+	GetAllSecrets() ([]models.PPPoESecret, error)
+	EnsureSecret(user, password, profile, localIP, remoteIP, comment string) (bool, error)
+	EnsureSecretProfile(user, profile string) (bool, error)
+	EnsureSecretDisabled(user string, disabled bool) (bool, error)
+	EnsureSecretAbsent(user string) (bool, error)
+	SetSecretFields(user string, fields map[string]string) error
+	KickUser(user string) (int, error)
+
+	GetAddressListEntries(lists []string) ([]models.AddressListEntry, error)
+	EnsureAddressList(ip, list, comment string) (bool, error)
+	EnsureAddressListAbsent(ip, list string) (bool, error)
+
+	GetActiveUsers() ([]models.ActiveUser, error)
+	GetSystemResource() (*models.SystemResource, error)
+	GetQueueTraffic(target string) (*models.TrafficStats, error)
+	GetQueueCounters() (map[string]models.ByteCounters, error)
+	GetIPPoolUsage() ([]models.IPPoolUsage, error)
+	RunBackup(name string) error
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"time"
+
+	"skynet-net-engine-api/internal/config"
+)
+
+// defaults seed the settings below, so tests and tools that never call Configure still work
+var defaults = config.Default()
+
+// Worker timings (workers.*)
+var (
+	MetricsInterval = time.Duration(defaults.Workers.MetricsInterval) // Between metrics refreshes
+	WarmupTimeout   = time.Duration(defaults.Workers.WarmupTimeout)   // WaitForReady gives up after this
+	ConnectRetry    = time.Duration(defaults.Workers.ConnectRetry)    // Wait after a failed connect
+	ReconnectDelay  = time.Duration(defaults.Workers.ReconnectDelay)  // Wait after a dropped connection
+	CommandBuffer   = defaults.Workers.CommandBuffer                  // Queued commands per router
+)
+
+// Anomaly allow-lists (policy.allow_*), read by NewAnomalyDetector
+var (
+	AllowMultiSessionUsers = defaults.Policy.AllowMultiSessionUsers
+	AllowMACChangeUsers    = defaults.Policy.AllowMACChangeUsers
+	AllowSharedMACs        = defaults.Policy.AllowSharedMACs
+)
+
+// Configure applies the engine settings; call it before InitPool
+func Configure(c *config.Config) {
+	RoutersFile, RoutersSource = c.Routers.File, c.Routers.Source
+
+	MetricsInterval = time.Duration(c.Workers.MetricsInterval)
+	WarmupTimeout = time.Duration(c.Workers.WarmupTimeout)
+	ConnectRetry = time.Duration(c.Workers.ConnectRetry)
+	ReconnectDelay = time.Duration(c.Workers.ReconnectDelay)
+	CommandBuffer = c.Workers.CommandBuffer
+
+	IsolationLists = c.Policy.IsolationLists
+	PoolAlertThreshold = c.Policy.PoolAlertThreshold
+	ReconcileMaxRemove = c.Policy.ReconcileMaxRemove
+	FUPCycleDay = c.Policy.FUPCycleDay
+	SyncDeletePolicy = c.Policy.SyncDeletePolicy
+	AllowMultiSessionUsers = c.Policy.AllowMultiSessionUsers
+	AllowMACChangeUsers = c.Policy.AllowMACChangeUsers
+	AllowSharedMACs = c.Policy.AllowSharedMACs
+
+	WebhookURL = c.Webhook.URL
+}
+
+// toSet turns a list into a lookup set, with every value passed through normalize
+func toSet(values []string, normalize func(string) string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if normalize != nil {
+			v = normalize(v)
+		}
+		set[v] = true
+	}
+	return set
+}
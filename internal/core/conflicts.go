@@ -0,0 +1,74 @@
+package core
+
+import (
+	"sort"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+// IPConflicts cross-checks session addresses and static remote addresses
+// across the fleet:
+//   - static: one remote address pinned on secrets of different usernames
+//   - session: one address held by sessions of different usernames
+//   - static_in_use: a session holds an address pinned on another user's secret
+func (p *Pool) IPConflicts() ([]models.IPConflict, error) {
+	statics, err := p.Store.GetStaticAddresses()
+	if err != nil {
+		return nil, err
+	}
+	sessions := p.Index.addressSnapshot()
+
+	conflicts := make([]models.IPConflict, 0)
+	for ip, users := range statics {
+		owners := make([]models.IPConflictOwner, 0, len(users))
+		names := make(map[string]bool)
+		for _, u := range users {
+			owners = append(owners, models.IPConflictOwner{Username: u.Username, RouterID: u.RouterID})
+			names[u.Username] = true
+		}
+		if len(names) > 1 {
+			conflicts = append(conflicts, models.IPConflict{Address: ip, Kind: "static", Secrets: owners})
+		}
+
+		for _, s := range sessions[ip] {
+			if !names[s.Name] {
+				conflicts = append(conflicts, models.IPConflict{Address: ip, Kind: "static_in_use", Sessions: sessions[ip], Secrets: owners})
+				break
+			}
+		}
+	}
+
+	for ip, held := range sessions {
+		if countUsernames(held) > 1 {
+			conflicts = append(conflicts, models.IPConflict{Address: ip, Kind: "session", Sessions: held})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Address != conflicts[j].Address {
+			return conflicts[i].Address < conflicts[j].Address
+		}
+		return conflicts[i].Kind < conflicts[j].Kind
+	})
+	return conflicts, nil
+}
+
+// GetAllPools returns the cached IP pool utilization of every router
+func (p *Pool) GetAllPools() []models.IPPoolUsage {
+	p.Lock.RLock()
+	defer p.Lock.RUnlock()
+
+	total := make([]models.IPPoolUsage, 0)
+	for _, w := range p.Workers {
+		w.Lock.RLock()
+		total = append(total, w.IPPools...)
+		w.Lock.RUnlock()
+	}
+	sort.Slice(total, func(i, j int) bool {
+		if total[i].RouterID != total[j].RouterID {
+			return total[i].RouterID < total[j].RouterID
+		}
+		return total[i].Name < total[j].Name
+	})
+	return total
+}
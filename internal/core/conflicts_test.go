@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPConflicts(t *testing.T) {
+	type static struct {
+		user     string
+		routerID int
+		address  string
+	}
+	type found struct{ address, kind string }
+
+	for _, tc := range []struct {
+		name     string
+		statics  []static
+		sessions map[int][]models.ActiveUser
+		want     []found
+	}{
+		{
+			name:    "no conflicts",
+			statics: []static{{"alice", 1, "10.0.0.5"}, {"alice", 2, "10.0.0.5"}}, // Same user on two routers
+			sessions: map[int][]models.ActiveUser{
+				1: {{Name: "alice", Address: "10.0.0.5", RouterID: 1}},
+				2: {{Name: "bob", Address: "10.0.0.6", RouterID: 2}},
+			},
+		},
+		{
+			name:    "static pinned on two users across routers",
+			statics: []static{{"alice", 1, "10.0.0.5"}, {"bob", 2, "10.0.0.5"}},
+			want:    []found{{"10.0.0.5", "static"}},
+		},
+		{
+			name: "active address held by two users",
+			sessions: map[int][]models.ActiveUser{
+				1: {{Name: "alice", Address: "10.0.0.7", RouterID: 1}},
+				2: {{Name: "bob", Address: "10.0.0.7", RouterID: 2}},
+			},
+			want: []found{{"10.0.0.7", "session"}},
+		},
+		{
+			name:    "static address in use by someone else",
+			statics: []static{{"alice", 1, "10.0.0.8"}},
+			sessions: map[int][]models.ActiveUser{
+				2: {{Name: "bob", Address: "10.0.0.8", RouterID: 2}},
+			},
+			want: []found{{"10.0.0.8", "static_in_use"}},
+		},
+		{
+			name:    "all at once",
+			statics: []static{{"alice", 1, "10.0.0.9"}, {"bob", 2, "10.0.0.9"}},
+			sessions: map[int][]models.ActiveUser{
+				1: {{Name: "alice", Address: "10.0.0.9", RouterID: 1}},
+				2: {{Name: "carol", Address: "10.0.0.9", RouterID: 2}},
+			},
+			want: []found{{"10.0.0.9", "session"}, {"10.0.0.9", "static"}, {"10.0.0.9", "static_in_use"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := newTestStore(t)
+			for _, s := range tc.statics {
+				require.NoError(t, st.UpsertUser(s.user, s.routerID, "10M", s.address, true))
+			}
+			p := &Pool{Workers: map[int]*Worker{}, Index: NewSubscriberIndex(), Store: st}
+			for routerID, users := range tc.sessions {
+				p.Index.Update(routerID, users)
+			}
+
+			conflicts, err := p.IPConflicts()
+			require.NoError(t, err)
+			got := make([]found, 0)
+			for _, c := range conflicts {
+				got = append(got, found{c.Address, c.Kind})
+			}
+			if tc.want == nil {
+				tc.want = []found{}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		require.NoError(t, err)
+		return v
+	}
+
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"0 22 * * *", "2026-03-10 21:30", "2026-03-10 22:00"},
+		{"0 22 * * *", "2026-03-10 22:00", "2026-03-11 22:00"},
+		{"*/15 * * * *", "2026-03-10 10:07", "2026-03-10 10:15"},
+		{"30 6 1 * *", "2026-03-10 10:00", "2026-04-01 06:30"},
+		{"0 0 * * 1-5", "2026-03-13 12:00", "2026-03-16 00:00"}, // Friday -> Monday
+		{"0 0 * * 7", "2026-03-10 00:00", "2026-03-15 00:00"},   // 7 is Sunday
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"0 12 1 * 0", "2026-03-02 00:00", "2026-03-08 12:00"}, // dom OR dow
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, at(tt.want), c.Next(at(tt.from)), tt.expr)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "0 0 30 2 *", "0 0 31 4,6,9,11 *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleNextRunNeverFires(t *testing.T) {
+	// A recurring job stored before impossible dates were rejected
+	j := models.ScheduledJob{Cron: "0 0 30 2 *", Status: models.SchedulePending}
+	scheduleNextRun(&j, time.Now())
+	assert.Equal(t, models.ScheduleFailed, j.Status)
+	assert.Nil(t, j.NextRunAt, "a zero next run would be due on every tick")
+	assert.NotEmpty(t, j.LastError)
+}
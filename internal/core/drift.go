@@ -0,0 +1,71 @@
+package core
+
+import (
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+)
+
+// driftFields maps reconcile fields to the names used in drift reports
+var driftFields = []struct{ field, name string }{
+	{"profile", "profile"},
+	{"remote-address", "remote_address"},
+	{"disabled", "enabled"},
+}
+
+// CompareSecrets sorts secrets into those only on the router, only in the
+// DB, and on both with a different profile, remote address or enabled flag.
+// It is the plan a "db" reconciliation would make, so both agree on what
+// differs; users in managed are left out the same way.
+func CompareSecrets(secrets []models.PPPoESecret, users map[string]database.DBUser, managed map[string]bool) models.DriftReport {
+	report := models.DriftReport{
+		Managed:    len(managed),
+		OnlyRouter: make([]models.SecretDrift, 0),
+		OnlyDB:     make([]models.SecretDrift, 0),
+		Mismatched: make([]models.SecretDrift, 0),
+	}
+
+	onRouter := make(map[string]*models.SecretState, len(secrets))
+	for _, s := range secrets {
+		onRouter[s.Name] = &models.SecretState{Profile: s.Profile, RemoteAddress: s.RemoteAddress, Enabled: !s.Disabled}
+	}
+	inDB := func(name string) *models.SecretState {
+		u := users[name]
+		return &models.SecretState{Profile: u.Profile, RemoteAddress: u.RemoteAddress, Enabled: u.IsEnabled}
+	}
+
+	// Changes come sorted by username within creates/updates and orphans
+	changes, inSync := PlanReconcile(desiredFromUsers(users), secrets, OrphanRemove, managed)
+	report.InSync = inSync
+	for _, c := range changes {
+		switch c.Action {
+		case models.SecretCreate:
+			report.OnlyDB = append(report.OnlyDB, models.SecretDrift{Username: c.Username, DB: inDB(c.Username)})
+		case models.SecretRemove:
+			report.OnlyRouter = append(report.OnlyRouter, models.SecretDrift{Username: c.Username, Router: onRouter[c.Username]})
+		default:
+			var fields []string
+			for _, f := range driftFields {
+				if _, ok := c.Fields[f.field]; ok {
+					fields = append(fields, f.name)
+				}
+			}
+			report.Mismatched = append(report.Mismatched, models.SecretDrift{
+				Username: c.Username, Fields: fields, Router: onRouter[c.Username], DB: inDB(c.Username),
+			})
+		}
+	}
+	return report
+}
+
+// Drift compares secrets read from the router with the worker's pppoe_users rows
+func (w *Worker) Drift(secrets []models.PPPoESecret) (models.DriftReport, error) {
+	users, err := w.store.GetUsersByRouter(w.Router.ID)
+	if err != nil {
+		return models.DriftReport{}, err
+	}
+	managed, err := w.managedUsers()
+	if err != nil {
+		return models.DriftReport{}, err
+	}
+	return CompareSecrets(secrets, users, managed), nil
+}
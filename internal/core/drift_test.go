@@ -0,0 +1,37 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSecrets(t *testing.T) {
+	secrets := []models.PPPoESecret{
+		{Name: "alice", Profile: "10M"},
+		{Name: "bob", Profile: "20M", RemoteAddress: "10.0.0.5", Disabled: true},
+		{Name: "carol", Profile: "10M"},
+		{Name: "erin", Profile: "isolir", Disabled: true},          // Suspended
+		{Name: "frank", Profile: "10M", RemoteAddress: "10.0.0.8"}, // Dynamic address in the DB
+	}
+	users := map[string]database.DBUser{
+		"alice": {Username: "alice", Profile: "10M", IsEnabled: true},
+		"bob":   {Username: "bob", Profile: "10M", RemoteAddress: "10.0.0.5", IsEnabled: true},
+		"dave":  {Username: "dave", Profile: "10M", IsEnabled: true},
+		"erin":  {Username: "erin", Profile: "10M", IsEnabled: true},
+		"frank": {Username: "frank", Profile: "10M", IsEnabled: true},
+	}
+
+	r := CompareSecrets(secrets, users, map[string]bool{"erin": true})
+	assert.Equal(t, 2, r.InSync)
+	assert.Equal(t, 1, r.Managed)
+	assert.Equal(t, []models.SecretDrift{{Username: "carol", Router: &models.SecretState{Profile: "10M", Enabled: true}}}, r.OnlyRouter)
+	assert.Equal(t, []models.SecretDrift{{Username: "dave", DB: &models.SecretState{Profile: "10M", Enabled: true}}}, r.OnlyDB)
+	assert.Len(t, r.Mismatched, 1)
+	assert.Equal(t, []string{"profile", "enabled"}, r.Mismatched[0].Fields)
+	assert.Equal(t, "20M", r.Mismatched[0].Router.Profile)
+	assert.Equal(t, "10M", r.Mismatched[0].DB.Profile)
+}
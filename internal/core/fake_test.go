@@ -0,0 +1,244 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errFake = errors.New("router said no")
+
+// TestMain sets up the logger once, since webhook goroutines from earlier tests
+// may still be logging, and turns webhooks off so no test posts to a real URL
+func TestMain(m *testing.M) {
+	logger.Init()
+	WebhookURL = ""
+	os.Exit(m.Run())
+}
+
+// newTestStore is a migrated in-memory SQLite store for one test
+func newTestStore(t *testing.T) database.Store {
+	t.Helper()
+
+	st, err := database.Open(database.DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	_, err = st.MigrateUp(0)
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+// fakeClient is an in-memory router. fail makes a method return an error
+// without doing anything; failAfter makes its next call do the work and then
+// fail, like a connection dropping before the reply.
+type fakeClient struct {
+	mu        sync.Mutex
+	secrets   map[string]*models.PPPoESecret
+	active    []models.ActiveUser
+	fail      map[string]error
+	failAfter map[string]error
+	kicked    []string
+	removed   []string
+}
+
+func newFakeClient(secrets ...models.PPPoESecret) *fakeClient {
+	f := &fakeClient{
+		secrets:   make(map[string]*models.PPPoESecret),
+		fail:      make(map[string]error),
+		failAfter: make(map[string]error),
+	}
+	for i := range secrets {
+		s := secrets[i]
+		f.secrets[s.Name] = &s
+	}
+	return f
+}
+
+// newTestWorker is an online worker on router 1 talking to client
+func newTestWorker(st database.Store, client *fakeClient) *Worker {
+	w := NewWorker(models.Router{ID: 1, Name: "test"}, st, nil)
+	w.Client = client
+	w.IsOnline = true
+	return w
+}
+
+func (f *fakeClient) secret(name string) models.PPPoESecret {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.secrets[name]
+}
+
+// call runs fn unless method is set to fail
+func (f *fakeClient) call(method string, fn func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail[method]; err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	err := f.failAfter[method]
+	delete(f.failAfter, method)
+	return err
+}
+
+func (f *fakeClient) Close() {}
+
+func (f *fakeClient) GetSecret(user string) (*models.PPPoESecret, error) {
+	var out *models.PPPoESecret
+	err := f.call("GetSecret", func() error {
+		if s, ok := f.secrets[user]; ok {
+			copied := *s
+			out = &copied
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (f *fakeClient) GetAllSecrets() ([]models.PPPoESecret, error) {
+	var out []models.PPPoESecret
+	err := f.call("GetAllSecrets", func() error {
+		for _, s := range f.secrets {
+			out = append(out, *s)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+		return nil
+	})
+	return out, err
+}
+
+func (f *fakeClient) EnsureSecret(user, password, profile, localIP, remoteIP, comment string) (bool, error) {
+	changed := false
+	err := f.call("EnsureSecret", func() error {
+		if _, ok := f.secrets[user]; !ok {
+			s := &models.PPPoESecret{Name: user, Profile: profile, RemoteAddress: remoteIP}
+			s.LocalAddress, s.Comment = localIP, comment
+			f.secrets[user] = s
+			changed = true
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func (f *fakeClient) EnsureSecretProfile(user, profile string) (bool, error) {
+	changed := false
+	err := f.call("EnsureSecretProfile", func() error {
+		s, ok := f.secrets[user]
+		if !ok {
+			return errors.New("user not found")
+		}
+		changed = s.Profile != profile
+		s.Profile = profile
+		return nil
+	})
+	return changed, err
+}
+
+func (f *fakeClient) EnsureSecretDisabled(user string, disabled bool) (bool, error) {
+	changed := false
+	err := f.call("EnsureSecretDisabled", func() error {
+		s, ok := f.secrets[user]
+		if !ok {
+			return errors.New("user not found")
+		}
+		changed = s.Disabled != disabled
+		s.Disabled = disabled
+		return nil
+	})
+	return changed, err
+}
+
+func (f *fakeClient) EnsureSecretAbsent(user string) (bool, error) {
+	changed := false
+	err := f.call("EnsureSecretAbsent", func() error {
+		_, changed = f.secrets[user]
+		delete(f.secrets, user)
+		f.removed = append(f.removed, user)
+		return nil
+	})
+	return changed, err
+}
+
+func (f *fakeClient) SetSecretFields(user string, fields map[string]string) error {
+	return f.call("SetSecretFields", func() error {
+		s, ok := f.secrets[user]
+		if !ok {
+			return errors.New("user not found")
+		}
+		for k, v := range fields {
+			switch k {
+			case "profile":
+				s.Profile = v
+			case "disabled":
+				s.Disabled = v == "yes" || v == "true"
+			case "remote-address":
+				s.RemoteAddress = v
+			case "local-address":
+				s.LocalAddress = v
+			case "comment":
+				s.Comment = v
+			}
+		}
+		return nil
+	})
+}
+
+func (f *fakeClient) KickUser(user string) (int, error) {
+	err := f.call("KickUser", func() error {
+		f.kicked = append(f.kicked, user)
+		return nil
+	})
+	return 0, err
+}
+
+func (f *fakeClient) GetAddressListEntries(lists []string) ([]models.AddressListEntry, error) {
+	return nil, f.call("GetAddressListEntries", func() error { return nil })
+}
+
+func (f *fakeClient) EnsureAddressList(ip, list, comment string) (bool, error) {
+	return true, f.call("EnsureAddressList", func() error { return nil })
+}
+
+func (f *fakeClient) EnsureAddressListAbsent(ip, list string) (bool, error) {
+	return true, f.call("EnsureAddressListAbsent", func() error { return nil })
+}
+
+func (f *fakeClient) GetActiveUsers() ([]models.ActiveUser, error) {
+	var out []models.ActiveUser
+	err := f.call("GetActiveUsers", func() error {
+		out = append(out, f.active...)
+		return nil
+	})
+	return out, err
+}
+
+func (f *fakeClient) GetSystemResource() (*models.SystemResource, error) {
+	return &models.SystemResource{}, f.call("GetSystemResource", func() error { return nil })
+}
+
+func (f *fakeClient) GetQueueTraffic(target string) (*models.TrafficStats, error) {
+	return &models.TrafficStats{}, f.call("GetQueueTraffic", func() error { return nil })
+}
+
+func (f *fakeClient) GetQueueCounters() (map[string]models.ByteCounters, error) {
+	return map[string]models.ByteCounters{}, f.call("GetQueueCounters", func() error { return nil })
+}
+
+func (f *fakeClient) GetIPPoolUsage() ([]models.IPPoolUsage, error) {
+	return nil, f.call("GetIPPoolUsage", func() error { return nil })
+}
+
+func (f *fakeClient) RunBackup(name string) error {
+	return f.call("RunBackup", func() error { return nil })
+}
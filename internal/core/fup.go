@@ -0,0 +1,232 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// fupInterval is how often quotas are evaluated
+const fupInterval = 5 * time.Minute
+
+// FUPCycleDay is the day of month billing cycles start on (policy.fup_cycle_day, 1-28)
+var FUPCycleDay = defaults.Policy.FUPCycleDay
+
+// CycleStart returns the start of the billing cycle containing t
+func CycleStart(t time.Time) time.Time {
+	start := time.Date(t.Year(), t.Month(), FUPCycleDay, 0, 0, 0, 0, t.Location())
+	if t.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+func fupKey(username string, routerID int) string {
+	return username + "|" + strconv.Itoa(routerID)
+}
+
+// StartFUP runs the fair-usage engine in the background
+func (p *Pool) StartFUP() {
+	go func() {
+		ticker := time.NewTicker(fupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			p.EnforceFUP()
+		}
+	}()
+}
+
+// EnforceFUP restores subscribers whose cycle has rolled over, then throttles
+// or isolates subscribers whose usage this cycle exceeds their profile's quota.
+// Router changes go through the workers' CmdUpdateSecret and CmdIsolate paths.
+func (p *Pool) EnforceFUP() {
+	if database.Offline().Degraded {
+		return // Limits applied now could not be recorded, and would be applied again every run
+	}
+	now := time.Now()
+	cycle := CycleStart(now)
+
+	states, err := p.Store.GetFUPStates()
+	if err != nil {
+		return
+	}
+	limited := make(map[string]bool, len(states))
+	for _, s := range states {
+		if s.CycleStart.Before(cycle) {
+			p.restoreFUP(s, now)
+			continue
+		}
+		limited[fupKey(s.Username, s.RouterID)] = true
+	}
+
+	rules, err := p.Store.GetFUPRules()
+	if err != nil {
+		return
+	}
+	byProfile := make(map[string]models.FUPRule, len(rules))
+	for _, r := range rules {
+		if r.Enabled {
+			byProfile[r.Profile] = r
+		}
+	}
+	if len(byProfile) == 0 {
+		return
+	}
+
+	totals, err := p.Store.GetUsageTotals(cycle)
+	if err != nil {
+		return
+	}
+
+	for routerID, usage := range totals {
+		users, err := p.Store.GetUsersByRouter(routerID)
+		if err != nil {
+			continue
+		}
+		// Suspended users keep their suspension; FUP would overwrite it
+		suspensions, err := p.Store.GetSuspensionsByRouter(routerID)
+		if err != nil {
+			continue
+		}
+		for username, used := range usage {
+			user, ok := users[username]
+			if !ok || limited[fupKey(username, routerID)] {
+				continue
+			}
+			if _, suspended := suspensions[username]; suspended {
+				continue
+			}
+			rule, ok := byProfile[user.Profile]
+			if !ok || used < rule.QuotaBytes {
+				continue
+			}
+			p.applyFUP(rule, user, used, cycle, now)
+		}
+	}
+}
+
+func (p *Pool) applyFUP(rule models.FUPRule, user database.DBUser, used int64, cycle, now time.Time) {
+	w := p.GetWorker(user.RouterID)
+	if w == nil {
+		return
+	}
+
+	var err error
+	switch rule.Action {
+	case models.FUPThrottle:
+		_, err = w.Execute(CmdUpdateSecret, map[string]string{"user": user.Username, "profile": rule.ThrottleProfile}, time.Minute)
+		if err == nil {
+			// The new profile only applies to new sessions
+			_, err = w.Execute(CmdKick, user.Username, time.Minute)
+		}
+	case models.FUPIsolate:
+		_, err = w.Execute(CmdIsolate, map[string]string{
+			"user": user.Username, "list": rule.AddressList, "action": "add", "comment": "FUP quota exceeded",
+		}, time.Minute)
+	}
+	if err != nil {
+		logger.Error("Failed to apply FUP", zap.String("user", user.Username), zap.Int("router_id", user.RouterID), zap.Error(err))
+		return // Retried on the next run
+	}
+
+	state := models.FUPState{
+		Username:        user.Username,
+		RouterID:        user.RouterID,
+		RuleID:          rule.ID,
+		Action:          rule.Action,
+		OriginalProfile: user.Profile,
+		ThrottleProfile: rule.ThrottleProfile,
+		AddressList:     rule.AddressList,
+		CycleStart:      cycle,
+		AppliedAt:       now,
+	}
+	p.Store.SaveFUPState(state)
+
+	event := models.FUPEvent{
+		Username: user.Username, RouterID: user.RouterID, RuleID: rule.ID, Event: "exceeded",
+		Action: rule.Action, UsageBytes: used, QuotaBytes: rule.QuotaBytes, CreatedAt: now,
+	}
+	p.Store.AddFUPEvent(event)
+	logger.Info("FUP quota exceeded", zap.String("user", user.Username), zap.String("action", rule.Action), zap.Int64("used", used))
+	SendWebhook("fup.exceeded", w.Router.ID, w.Router.Host, event)
+}
+
+func (p *Pool) restoreFUP(s models.FUPState, now time.Time) {
+	w := p.GetWorker(s.RouterID)
+	if w == nil {
+		return
+	}
+
+	var err error
+	switch s.Action {
+	case models.FUPThrottle:
+		err = w.restoreThrottle(s)
+	case models.FUPIsolate:
+		_, err = w.Execute(CmdIsolate, map[string]string{"user": s.Username, "list": s.AddressList, "action": "remove"}, time.Minute)
+	}
+	if err != nil {
+		logger.Error("Failed to restore FUP", zap.String("user", s.Username), zap.Int("router_id", s.RouterID), zap.Error(err))
+		return
+	}
+
+	p.Store.DeleteFUPState(s.Username, s.RouterID)
+
+	event := models.FUPEvent{
+		Username: s.Username, RouterID: s.RouterID, RuleID: s.RuleID, Event: "restored",
+		Action: s.Action, CreatedAt: now,
+	}
+	p.Store.AddFUPEvent(event)
+	logger.Info("FUP restored", zap.String("user", s.Username), zap.String("profile", s.OriginalProfile))
+	SendWebhook("fup.restored", w.Router.ID, w.Router.Host, event)
+}
+
+// restoreThrottle puts back the profile a throttled user had, unless the
+// profile was changed since (a plan change, or a suspension, which then
+// resumes to the original profile instead of the throttle one).
+func (w *Worker) restoreThrottle(s models.FUPState) error {
+	throttle := s.ThrottleProfile
+	if throttle == "" {
+		// States saved before the throttle profile was recorded
+		rules, err := w.store.GetFUPRules()
+		if err != nil {
+			return err
+		}
+		for _, r := range rules {
+			if r.ID == s.RuleID {
+				throttle = r.ThrottleProfile
+			}
+		}
+		if throttle == "" {
+			logger.Warn("FUP rule gone, leaving profile as is", zap.String("user", s.Username), zap.Int("rule_id", s.RuleID))
+			return nil
+		}
+	}
+
+	suspension, err := w.store.GetSuspension(s.Username, s.RouterID)
+	if err != nil {
+		return err
+	}
+	if suspension != nil {
+		if suspension.PreviousProfile != throttle {
+			return nil
+		}
+		return w.store.SetSuspensionPreviousProfile(s.Username, s.RouterID, s.OriginalProfile)
+	}
+
+	res, err := w.Execute(CmdUpdateSecret, map[string]string{
+		"user": s.Username, "profile": s.OriginalProfile, "if_profile": throttle,
+	}, time.Minute)
+	if err != nil {
+		return err
+	}
+	if c, _ := res.(Change); c.Changed {
+		_, err = w.Execute(CmdKick, s.Username, time.Minute)
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFUPPool is a pool with one running worker on router 1 and a throttle
+// rule moving 10M subscribers to 1M past 100 bytes
+func newFUPPool(t *testing.T, client *fakeClient) *Pool {
+	t.Helper()
+	st := newTestStore(t)
+	require.NoError(t, st.SaveFUPRule(models.FUPRule{
+		Profile: "10M", QuotaBytes: 100, Action: models.FUPThrottle, ThrottleProfile: "1M", Enabled: true,
+	}))
+	require.NoError(t, st.UpsertUser("alice", 1, "10M", "", true))
+
+	w := newTestWorker(st, client)
+	go w.handleCommands()
+	t.Cleanup(w.Stop)
+	return &Pool{Workers: map[int]*Worker{1: w}, Index: NewSubscriberIndex(), Store: st}
+}
+
+func useOverQuota(t *testing.T, st database.Store, username string) {
+	t.Helper()
+	hour := time.Now().Truncate(time.Hour)
+	require.NoError(t, st.AddUsage(1, hour, []models.ByteCounters{{Name: username, RX: 150, TX: 50}}))
+}
+
+// throttledState is alice's state left over from the previous cycle
+func throttledState(t *testing.T, st database.Store) {
+	t.Helper()
+	rules, err := st.GetFUPRules()
+	require.NoError(t, err)
+	require.NoError(t, st.SaveFUPState(models.FUPState{
+		Username: "alice", RouterID: 1, RuleID: rules[0].ID, Action: models.FUPThrottle,
+		OriginalProfile: "10M", ThrottleProfile: "1M",
+		CycleStart: CycleStart(time.Now()).AddDate(0, -1, 0), AppliedAt: time.Now().AddDate(0, 0, -7),
+	}))
+}
+
+func TestEnforceFUP(t *testing.T) {
+	t.Run("throttles once over quota", func(t *testing.T) {
+		client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "10M"})
+		p := newFUPPool(t, client)
+		useOverQuota(t, p.Store, "alice")
+
+		p.EnforceFUP()
+		assert.Equal(t, "1M", client.secret("alice").Profile)
+		assert.Equal(t, []string{"alice"}, client.kicked)
+		states, err := p.Store.GetFUPStates()
+		require.NoError(t, err)
+		require.Len(t, states, 1)
+		assert.Equal(t, "10M", states[0].OriginalProfile)
+		assert.Equal(t, "1M", states[0].ThrottleProfile)
+
+		p.EnforceFUP() // Already limited this cycle
+		assert.Equal(t, []string{"alice"}, client.kicked)
+	})
+
+	t.Run("restores on a new cycle", func(t *testing.T) {
+		client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "1M"})
+		p := newFUPPool(t, client)
+		throttledState(t, p.Store)
+
+		p.EnforceFUP()
+		assert.Equal(t, "10M", client.secret("alice").Profile)
+		assert.Equal(t, []string{"alice"}, client.kicked)
+		states, err := p.Store.GetFUPStates()
+		require.NoError(t, err)
+		assert.Empty(t, states)
+	})
+
+	t.Run("restore keeps a plan changed since", func(t *testing.T) {
+		client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "20M"})
+		p := newFUPPool(t, client)
+		throttledState(t, p.Store)
+
+		p.EnforceFUP()
+		assert.Equal(t, "20M", client.secret("alice").Profile)
+		assert.Empty(t, client.kicked)
+		states, err := p.Store.GetFUPStates()
+		require.NoError(t, err)
+		assert.Empty(t, states)
+	})
+
+	t.Run("restore of a suspended user resumes to the original profile", func(t *testing.T) {
+		client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "isolir"})
+		p := newFUPPool(t, client)
+		throttledState(t, p.Store)
+		require.NoError(t, p.Store.SaveSuspension(models.Suspension{
+			Username: "alice", RouterID: 1, Strategy: models.SuspendIsolir, Profile: "isolir",
+			PreviousProfile: "1M", CreatedAt: time.Now(),
+		}))
+
+		p.EnforceFUP()
+		assert.Equal(t, "isolir", client.secret("alice").Profile)
+		assert.Empty(t, client.kicked)
+		s, err := p.Store.GetSuspension("alice", 1)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		assert.Equal(t, "10M", s.PreviousProfile)
+	})
+
+	t.Run("skips suspended users", func(t *testing.T) {
+		client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "isolir"})
+		p := newFUPPool(t, client)
+		useOverQuota(t, p.Store, "alice")
+		require.NoError(t, p.Store.SaveSuspension(models.Suspension{
+			Username: "alice", RouterID: 1, Strategy: models.SuspendIsolir, Profile: "isolir",
+			PreviousProfile: "10M", CreatedAt: time.Now(),
+		}))
+
+		p.EnforceFUP()
+		assert.Equal(t, "isolir", client.secret("alice").Profile)
+		states, err := p.Store.GetFUPStates()
+		require.NoError(t, err)
+		assert.Empty(t, states)
+	})
+}
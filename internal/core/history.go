@@ -0,0 +1,128 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/mikrotik"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type sessionBatch struct {
+	routerID int
+	at       time.Time
+	users    []models.ActiveUser
+	events   []SessionEvent
+	resync   bool // A batch of this router was dropped before this one
+}
+
+// SessionRecorder writes connects and disconnects to pppoe_sessions. Batches
+// are processed in order by a single goroutine so the command loops never
+// wait on the database. When the queue is full a batch is dropped and the
+// router's next batch reconciles its open rows instead of replaying events.
+type SessionRecorder struct {
+	store      database.Store
+	queue      chan sessionBatch
+	reconciled map[int]bool // routers whose open rows were checked against the router; owned by run
+
+	mu      sync.Mutex
+	dropped map[int]bool // routers with a dropped batch not yet followed by a resync
+}
+
+func NewSessionRecorder(store database.Store) *SessionRecorder {
+	r := newSessionRecorder(store, 100)
+	go r.run()
+	return r
+}
+
+func newSessionRecorder(store database.Store, size int) *SessionRecorder {
+	return &SessionRecorder{
+		store:      store,
+		queue:      make(chan sessionBatch, size),
+		reconciled: make(map[int]bool),
+		dropped:    make(map[int]bool),
+	}
+}
+
+func (r *SessionRecorder) record(routerID int, users []models.ActiveUser, events []SessionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := sessionBatch{routerID: routerID, at: time.Now(), users: users, events: events, resync: r.dropped[routerID]}
+	select {
+	case r.queue <- b:
+		delete(r.dropped, routerID)
+	default:
+		r.dropped[routerID] = true
+		logger.Warn("Session history queue full, dropping batch", zap.Int("router_id", routerID), zap.Int("events", len(events)))
+	}
+}
+
+func (r *SessionRecorder) run() {
+	for b := range r.queue {
+		if !r.reconciled[b.routerID] || b.resync {
+			r.reconciled[b.routerID] = r.reconcile(b)
+			continue
+		}
+
+		for _, ev := range b.events {
+			switch ev.Type {
+			case SessionDisconnected:
+				r.store.CloseSession(b.routerID, ev.User.Name, ev.User.Address, b.at)
+			case SessionConnected:
+				r.store.OpenSession(newSession(ev.User, b.at))
+			}
+		}
+	}
+}
+
+// reconcile aligns the open rows left by a previous run with the sessions
+// that are actually up: rows still matching stay open, the rest are closed,
+// and sessions without a row are opened.
+func (r *SessionRecorder) reconcile(b sessionBatch) bool {
+	open, err := r.store.GetOpenSessions(b.routerID)
+	if err != nil {
+		return false
+	}
+
+	live := make(map[string]bool, len(b.users))
+	for _, u := range b.users {
+		live[sessionKey(u)] = true
+	}
+
+	known := make(map[string]bool, len(open))
+	stale := make([]models.Session, 0)
+	for _, s := range open {
+		key := sessionKey(models.ActiveUser{Name: s.Username, Address: s.Address, CallerID: s.CallerID})
+		if live[key] && !known[key] {
+			known[key] = true
+			continue
+		}
+		stale = append(stale, s)
+	}
+	r.store.CloseSessions(stale, b.at)
+
+	for _, u := range b.users {
+		if !known[sessionKey(u)] {
+			r.store.OpenSession(newSession(u, b.at))
+		}
+	}
+
+	logger.Info("Session history reconciled", zap.Int("router_id", b.routerID), zap.Int("open", len(b.users)), zap.Int("closed", len(stale)))
+	return true
+}
+
+// newSession backdates the start using the session uptime reported by the router
+func newSession(u models.ActiveUser, seenAt time.Time) models.Session {
+	return models.Session{
+		Username:  u.Name,
+		RouterID:  u.RouterID,
+		Address:   u.Address,
+		CallerID:  u.CallerID,
+		StartedAt: seenAt.Add(-mikrotik.ParseDuration(u.Uptime)).Truncate(time.Second),
+	}
+}
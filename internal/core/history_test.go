@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openSessions(t *testing.T, st database.Store) []string {
+	t.Helper()
+	open, err := st.GetOpenSessions(1)
+	require.NoError(t, err)
+	names := make([]string, 0, len(open))
+	for _, s := range open {
+		names = append(names, s.Username)
+	}
+	return names
+}
+
+func TestSessionRecorder(t *testing.T) {
+	st := newTestStore(t)
+	r := newSessionRecorder(st, 1)
+	go r.run()
+	t.Cleanup(func() { close(r.queue) })
+
+	alice := models.ActiveUser{Name: "alice", Address: "10.0.0.5", RouterID: 1}
+	bob := models.ActiveUser{Name: "bob", Address: "10.0.0.6", RouterID: 1}
+
+	// First batch reconciles, later ones apply their events
+	r.record(1, []models.ActiveUser{alice}, nil)
+	assert.Eventually(t, func() bool { return len(openSessions(t, st)) == 1 }, time.Second, 10*time.Millisecond)
+	r.record(1, []models.ActiveUser{alice, bob}, []SessionEvent{{Type: SessionConnected, User: bob}})
+	assert.Eventually(t, func() bool { return len(openSessions(t, st)) == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSessionRecorderResyncsAfterDrop(t *testing.T) {
+	st := newTestStore(t)
+	r := newSessionRecorder(st, 1)
+	alice := models.ActiveUser{Name: "alice", Address: "10.0.0.5", RouterID: 1}
+	bob := models.ActiveUser{Name: "bob", Address: "10.0.0.6", RouterID: 1}
+
+	r.record(1, []models.ActiveUser{alice}, nil)
+	// Queue full: alice's disconnect is lost
+	r.record(1, nil, []SessionEvent{{Type: SessionDisconnected, User: alice}})
+	assert.True(t, r.dropped[1])
+
+	go r.run()
+	t.Cleanup(func() { close(r.queue) })
+	assert.Eventually(t, func() bool { return len(openSessions(t, st)) == 1 }, time.Second, 10*time.Millisecond)
+
+	// The next batch only carries bob's connect, but resyncs against the full list
+	r.record(1, []models.ActiveUser{bob}, []SessionEvent{{Type: SessionConnected, User: bob}})
+	assert.False(t, r.dropped[1])
+	assert.Eventually(t, func() bool {
+		open := openSessions(t, st)
+		return len(open) == 1 && open[0] == "bob"
+	}, time.Second, 10*time.Millisecond)
+}
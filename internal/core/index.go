@@ -0,0 +1,106 @@
+package core
+
+import (
+	"strings"
+	"sync"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+// SubscriberIndex answers fleet-wide "where is this subscriber" questions
+// without walking every worker. It is rebuilt from each worker's ActiveUsers
+// whenever that worker refreshes.
+type SubscriberIndex struct {
+	mu       sync.RWMutex
+	byRouter map[int][]models.ActiveUser
+	byName   map[string][]models.ActiveUser
+	byIP     map[string][]models.ActiveUser
+	byMAC    map[string][]models.ActiveUser
+}
+
+func NewSubscriberIndex() *SubscriberIndex {
+	return &SubscriberIndex{
+		byRouter: make(map[int][]models.ActiveUser),
+		byName:   make(map[string][]models.ActiveUser),
+		byIP:     make(map[string][]models.ActiveUser),
+		byMAC:    make(map[string][]models.ActiveUser),
+	}
+}
+
+// Update replaces the sessions known for a router
+func (x *SubscriberIndex) Update(routerID int, users []models.ActiveUser) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.byRouter[routerID] = users
+
+	x.byName = make(map[string][]models.ActiveUser, len(x.byName))
+	x.byIP = make(map[string][]models.ActiveUser, len(x.byIP))
+	x.byMAC = make(map[string][]models.ActiveUser, len(x.byMAC))
+	for _, sessions := range x.byRouter {
+		for _, u := range sessions {
+			x.byName[u.Name] = append(x.byName[u.Name], u)
+			if u.Address != "" {
+				x.byIP[u.Address] = append(x.byIP[u.Address], u)
+			}
+			if u.CallerID != "" {
+				mac := NormalizeMAC(u.CallerID)
+				x.byMAC[mac] = append(x.byMAC[mac], u)
+			}
+		}
+	}
+}
+
+// ByName returns the active sessions of a username on all routers
+func (x *SubscriberIndex) ByName(username string) []models.ActiveUser {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]models.ActiveUser(nil), x.byName[username]...)
+}
+
+// ByIP returns the active sessions holding an address
+func (x *SubscriberIndex) ByIP(ip string) []models.ActiveUser {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]models.ActiveUser(nil), x.byIP[ip]...)
+}
+
+// ByMAC returns the active sessions dialed from a caller-id
+func (x *SubscriberIndex) ByMAC(mac string) []models.ActiveUser {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]models.ActiveUser(nil), x.byMAC[NormalizeMAC(mac)]...)
+}
+
+// snapshot copies the username and MAC lookups for analysis
+func (x *SubscriberIndex) snapshot() (map[string][]models.ActiveUser, map[string][]models.ActiveUser) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	byName := make(map[string][]models.ActiveUser, len(x.byName))
+	for k, v := range x.byName {
+		byName[k] = append([]models.ActiveUser(nil), v...)
+	}
+	byMAC := make(map[string][]models.ActiveUser, len(x.byMAC))
+	for k, v := range x.byMAC {
+		byMAC[k] = append([]models.ActiveUser(nil), v...)
+	}
+	return byName, byMAC
+}
+
+// addressSnapshot copies the IP lookup for analysis
+func (x *SubscriberIndex) addressSnapshot() map[string][]models.ActiveUser {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	byIP := make(map[string][]models.ActiveUser, len(x.byIP))
+	for k, v := range x.byIP {
+		byIP[k] = append([]models.ActiveUser(nil), v...)
+	}
+	return byIP
+}
+
+// NormalizeMAC makes caller-ids comparable regardless of case and separator
+func NormalizeMAC(mac string) string {
+	return strings.ToUpper(strings.ReplaceAll(mac, "-", ":"))
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriberIndex(t *testing.T) {
+	x := NewSubscriberIndex()
+	x.Update(1, []models.ActiveUser{
+		{Name: "alice", Address: "10.0.0.5", CallerID: "aa-bb-cc-dd-ee-ff", RouterID: 1},
+		{Name: "bob", Address: "10.0.0.6", RouterID: 1},
+	})
+	x.Update(2, []models.ActiveUser{
+		{Name: "alice", Address: "10.1.0.5", CallerID: "AA:BB:CC:DD:EE:FF", RouterID: 2},
+	})
+
+	assert.Len(t, x.ByName("alice"), 2)
+	assert.Len(t, x.ByMAC("aa:bb:cc:dd:ee:ff"), 2, "caller-ids are normalized")
+	assert.Equal(t, "bob", x.ByIP("10.0.0.6")[0].Name)
+	assert.Empty(t, x.ByName("carol"))
+
+	// A refresh replaces only that router's sessions
+	x.Update(1, []models.ActiveUser{{Name: "bob", Address: "10.0.0.7", RouterID: 1}})
+	assert.Equal(t, []models.ActiveUser{{Name: "alice", Address: "10.1.0.5", CallerID: "AA:BB:CC:DD:EE:FF", RouterID: 2}}, x.ByName("alice"))
+	assert.Empty(t, x.ByIP("10.0.0.6"))
+	assert.Len(t, x.ByIP("10.0.0.7"), 1)
+
+	x.Update(2, nil)
+	assert.Empty(t, x.ByName("alice"))
+	assert.Empty(t, x.ByMAC("AA:BB:CC:DD:EE:FF"))
+}
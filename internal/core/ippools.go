@@ -0,0 +1,48 @@
+package core
+
+import (
+	"time"
+
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// poolCheckInterval throttles /ip/pool/used reads, which can be large
+const poolCheckInterval = time.Minute
+
+// PoolAlertThreshold is the utilization percent at which a pool raises an
+// alert (policy.pool_alert_threshold)
+var PoolAlertThreshold = defaults.Policy.PoolAlertThreshold
+
+// refreshPools updates the IP pool cache and fires an alert when a pool
+// crosses PoolAlertThreshold, and again once it drops back below.
+func (w *Worker) refreshPools() {
+	if time.Since(w.poolsCheckedAt) < poolCheckInterval {
+		return
+	}
+	w.poolsCheckedAt = time.Now()
+
+	pools, err := w.Client.GetIPPoolUsage()
+	if err != nil {
+		logger.Error("Failed to fetch IP pools", zap.String("host", w.Router.Host), zap.Error(err))
+		return
+	}
+
+	w.Lock.Lock()
+	w.IPPools = pools
+	w.Lock.Unlock()
+
+	for _, p := range pools {
+		over := p.Size > 0 && p.Utilization >= PoolAlertThreshold
+		switch {
+		case over && !w.poolAlerts[p.Name]:
+			w.poolAlerts[p.Name] = true
+			logger.Warn("IP pool nearly exhausted", zap.String("router", w.Router.Name), zap.String("pool", p.Name), zap.Float64("utilization", p.Utilization))
+			SendWebhook("pool.threshold_exceeded", w.Router.ID, w.Router.Host, p)
+		case !over && w.poolAlerts[p.Name]:
+			delete(w.poolAlerts, p.Name)
+			SendWebhook("pool.recovered", w.Router.ID, w.Router.Host, p)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"net"
+	"strings"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// IsolationLists are the firewall address lists that mark a subscriber as
+// isolated (policy.isolation_lists)
+var IsolationLists = defaults.Policy.IsolationLists
+
+// indexAddressList maps plain-IP entries by address. Subnet entries are
+// matched by scanning, as they are rare in isolation lists.
+func indexAddressList(entries []models.AddressListEntry) map[string]models.AddressListEntry {
+	index := make(map[string]models.AddressListEntry, len(entries))
+	for _, e := range entries {
+		if !strings.Contains(e.Address, "/") {
+			index[e.Address] = e
+		}
+	}
+	return index
+}
+
+// IsolationOf reports why a subscriber is isolated: the cached address-list
+// entry covering its IP, or its username-based isolation while it is offline.
+func (w *Worker) IsolationOf(username, ip string) *models.AddressListEntry {
+	w.Lock.RLock()
+	defer w.Lock.RUnlock()
+
+	if ip != "" {
+		if e, ok := w.addressIndex[ip]; ok {
+			return &e
+		}
+		if addr := net.ParseIP(ip); addr != nil {
+			for _, e := range w.AddressLists {
+				if _, subnet, err := net.ParseCIDR(e.Address); err == nil && subnet.Contains(addr) {
+					return &e
+				}
+			}
+		}
+	}
+
+	for _, iso := range w.Isolations {
+		if iso.Username == username {
+			return &models.AddressListEntry{List: iso.List, Address: iso.Address, Comment: iso.Comment}
+		}
+	}
+	return nil
+}
+
+func isolationKey(username, list string) string {
+	return username + "|" + list
+}
+
+// loadIsolations restores username-based isolations from the database.
+// The next metrics refresh re-points all of them at the current sessions.
+func (w *Worker) loadIsolations() {
+	isolations, err := w.store.GetIsolationsByRouter(w.Router.ID)
+	if err != nil {
+		return
+	}
+
+	w.Lock.Lock()
+	w.Isolations = make(map[string]*models.Isolation, len(isolations))
+	for i := range isolations {
+		iso := isolations[i]
+		w.Isolations[isolationKey(iso.Username, iso.List)] = &iso
+	}
+	w.isolationsSynced = false
+	w.isolationsLoaded = true
+	w.Lock.Unlock()
+}
+
+// persistIsolations writes isolations changed while the database was down back
+// to it. If they had been loaded before the outage, memory is the truth and
+// stale rows are deleted; otherwise both sides are merged.
+func (w *Worker) persistIsolations() {
+	stored, err := w.store.GetIsolationsByRouter(w.Router.ID)
+	if err != nil {
+		return
+	}
+
+	w.Lock.RLock()
+	loaded := w.isolationsLoaded
+	inMemory := make([]models.Isolation, 0, len(w.Isolations))
+	for _, iso := range w.Isolations {
+		inMemory = append(inMemory, *iso)
+	}
+	w.Lock.RUnlock()
+
+	keep := make(map[string]bool, len(inMemory))
+	for _, iso := range inMemory {
+		keep[isolationKey(iso.Username, iso.List)] = true
+		w.store.SaveIsolation(iso)
+	}
+	if loaded {
+		for _, iso := range stored {
+			if !keep[isolationKey(iso.Username, iso.List)] {
+				w.store.DeleteIsolation(iso.Username, w.Router.ID, iso.List)
+			}
+		}
+	}
+	w.loadIsolations()
+}
+
+// resolveAddress finds the IP a subscriber currently holds: the live session
+// first, then the static remote-address pinned on the secret.
+func (w *Worker) resolveAddress(username string) string {
+	w.Lock.RLock()
+	for _, u := range w.ActiveUsers {
+		if u.Name == username {
+			w.Lock.RUnlock()
+			return u.Address
+		}
+	}
+	w.Lock.RUnlock()
+
+	user, err := w.store.GetUser(w.Router.ID, username)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.RemoteAddress
+}
+
+// repointIsolation moves an isolation's address-list entry to a new IP and
+// makes sure the entry is really there. An empty address only removes the
+// old entry. It reports whether the router or the isolation changed.
+func (w *Worker) repointIsolation(iso *models.Isolation, address string) (bool, error) {
+	changed := false
+	if iso.Address != "" && iso.Address != address {
+		if _, err := w.Client.EnsureAddressListAbsent(iso.Address, iso.List); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if address != "" {
+		added, err := w.Client.EnsureAddressList(address, iso.List, iso.Comment)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || added
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if iso.Address != address {
+		logger.Info("Isolation re-pointed", zap.String("router", w.Router.Name), zap.String("user", iso.Username),
+			zap.String("from", iso.Address), zap.String("to", address))
+	}
+
+	w.Lock.Lock()
+	iso.Address = address
+	w.Lock.Unlock()
+	return true, nil
+}
+
+// followIsolations keeps username-based isolations on the subscriber's
+// current IP. Only users touched by a session change are re-checked, except
+// for the first pass after loading, which checks everyone.
+func (w *Worker) followIsolations(events []SessionEvent) {
+	touched := make(map[string]bool, len(events))
+	for _, ev := range events {
+		touched[ev.User.Name] = true
+	}
+
+	w.Lock.RLock()
+	full := !w.isolationsSynced
+	pending := make([]*models.Isolation, 0)
+	for _, iso := range w.Isolations {
+		if full || touched[iso.Username] {
+			pending = append(pending, iso)
+		}
+	}
+	w.Lock.RUnlock()
+
+	for _, iso := range pending {
+		changed, err := w.repointIsolation(iso, w.resolveAddress(iso.Username))
+		if err != nil {
+			logger.Error("Failed to re-point isolation", zap.String("router", w.Router.Name), zap.String("user", iso.Username), zap.Error(err))
+			continue
+		}
+		if changed {
+			w.store.SaveIsolation(*iso)
+		}
+	}
+
+	w.Lock.Lock()
+	w.isolationsSynced = true
+	w.Lock.Unlock()
+}
+
+// isolateUser adds or lifts a username-based isolation
+func (w *Worker) isolateUser(payload map[string]string) (interface{}, error) {
+	username, list := payload["user"], payload["list"]
+	key := isolationKey(username, list)
+
+	w.Lock.RLock()
+	iso, exists := w.Isolations[key]
+	w.Lock.RUnlock()
+
+	if payload["action"] == "add" {
+		if !exists {
+			iso = &models.Isolation{Username: username, RouterID: w.Router.ID, List: list}
+		}
+		w.Lock.Lock()
+		iso.Comment = payload["comment"]
+		w.Lock.Unlock()
+
+		changed, err := w.repointIsolation(iso, w.resolveAddress(username))
+		if err != nil {
+			return nil, err
+		}
+
+		w.Lock.Lock()
+		w.Isolations[key] = iso
+		w.Lock.Unlock()
+		w.store.SaveIsolation(*iso)
+
+		return map[string]interface{}{"ip": iso.Address, "changed": changed || !exists}, nil
+	}
+
+	// Lift: drop whatever entry we placed, or the user's current IP if the
+	// isolation was never tracked (e.g. added by IP before this feature)
+	address, changed := "", false
+	if exists {
+		address = iso.Address
+		if _, err := w.repointIsolation(iso, ""); err != nil {
+			return nil, err
+		}
+		w.Lock.Lock()
+		delete(w.Isolations, key)
+		w.Lock.Unlock()
+		w.store.DeleteIsolation(username, w.Router.ID, list)
+		changed = true
+	} else if address = w.resolveAddress(username); address != "" {
+		removed, err := w.Client.EnsureAddressListAbsent(address, list)
+		if err != nil {
+			return nil, err
+		}
+		changed = removed
+	}
+
+	return map[string]interface{}{"ip": address, "changed": changed}, nil
+}
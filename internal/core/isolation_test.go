@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsolationOf(t *testing.T) {
+	w := NewWorker(models.Router{ID: 1}, nil, nil)
+	w.AddressLists = []models.AddressListEntry{
+		{List: "ISOLATED", Address: "10.0.0.5", Comment: "unpaid"},
+		{List: "BLOCKED", Address: "10.9.0.0/24"},
+	}
+	w.addressIndex = indexAddressList(w.AddressLists)
+	w.Isolations[isolationKey("dave", "ISOLATED")] = &models.Isolation{Username: "dave", List: "ISOLATED", Comment: "by username"}
+
+	// Exact IP match carries the list and comment
+	iso := w.IsolationOf("alice", "10.0.0.5")
+	assert.Equal(t, "ISOLATED", iso.List)
+	assert.Equal(t, "unpaid", iso.Comment)
+
+	// Subnet entries cover every address inside them
+	assert.Equal(t, "BLOCKED", w.IsolationOf("bob", "10.9.0.77").List)
+
+	// Offline users fall back to their username-based isolation
+	assert.Equal(t, "by username", w.IsolationOf("dave", "").Comment)
+
+	assert.Nil(t, w.IsolationOf("carol", "10.0.0.6"))
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// JobTimeout bounds how long an async job waits for its router
+const JobTimeout = 10 * time.Minute
+
+// JobFunc does the work of an async job. It may report progress (percent).
+type JobFunc func(progress func(int)) (interface{}, error)
+
+// SubmitJob persists a queued job for w and runs it in the background.
+// Completion is delivered as a "job.completed" or "job.failed" webhook.
+func SubmitJob(jobType string, w *Worker, run JobFunc) (models.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return models.Job{}, err
+	}
+	job := models.Job{
+		ID:        id,
+		Type:      jobType,
+		RouterID:  w.Router.ID,
+		Status:    models.JobQueued,
+		CreatedAt: time.Now(),
+	}
+	if err := w.store.SaveJob(job); err != nil {
+		return job, err
+	}
+
+	go func(job models.Job) {
+		started := time.Now()
+		job.Status = models.JobRunning
+		job.StartedAt = &started
+		w.store.SaveJob(job)
+
+		// Progress comes from the worker and may still arrive after run gave
+		// up waiting, so it is serialized with the final save and dropped after it
+		var mu sync.Mutex
+		done := false
+		progress := func(pct int) {
+			mu.Lock()
+			defer mu.Unlock()
+			if done || pct <= job.Progress {
+				return
+			}
+			job.Progress = pct
+			w.store.SaveJob(job)
+		}
+		result, err := run(progress)
+
+		mu.Lock()
+		defer mu.Unlock()
+		done = true
+		finished := time.Now()
+		job.FinishedAt = &finished
+		event := "job.completed"
+		if err != nil {
+			job.Status = models.JobFailed
+			job.Error = err.Error()
+			event = "job.failed"
+			logger.Warn("Job failed", zap.String("id", job.ID), zap.String("type", job.Type), zap.Error(err))
+		} else {
+			job.Status = models.JobSucceeded
+			job.Progress = 100
+			job.Result = result
+			logger.Info("Job completed", zap.String("id", job.ID), zap.String("type", job.Type), zap.Duration("took", finished.Sub(started)))
+		}
+		w.store.SaveJob(job)
+		SendWebhook(event, w.Router.ID, w.Router.Host, job)
+	}(job)
+
+	return job, nil
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitJob polls the job until it has finished
+func waitJob(t *testing.T, st database.Store, id string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = st.GetJob(id)
+		require.NoError(t, err)
+		return job != nil && job.FinishedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestSubmitJob(t *testing.T) {
+	st := newTestStore(t)
+	w := newTestWorker(st, newFakeClient())
+
+	release := make(chan struct{})
+	var report func(int)
+	job, err := SubmitJob("sync", w, func(progress func(int)) (interface{}, error) {
+		report = progress
+		progress(40)
+		<-release
+		return map[string]interface{}{"created": 2.0}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, job.ID, 32)
+	assert.Equal(t, models.JobQueued, job.Status)
+
+	require.Eventually(t, func() bool {
+		got, err := st.GetJob(job.ID)
+		return err == nil && got != nil && got.Status == models.JobRunning && got.Progress == 40
+	}, 5*time.Second, 10*time.Millisecond)
+	close(release)
+
+	got := waitJob(t, st, job.ID)
+	assert.Equal(t, models.JobSucceeded, got.Status)
+	assert.Equal(t, 100, got.Progress)
+	report(60) // A late report from the worker does not reopen the job
+	got = waitJob(t, st, job.ID)
+	assert.Equal(t, models.JobSucceeded, got.Status)
+	assert.Equal(t, 100, got.Progress)
+	assert.Equal(t, map[string]interface{}{"created": 2.0}, got.Result)
+	assert.NotNil(t, got.StartedAt)
+	assert.Empty(t, got.Error)
+
+	failed, err := SubmitJob("backup", w, func(func(int)) (interface{}, error) { return nil, errFake })
+	require.NoError(t, err)
+	assert.NotEqual(t, job.ID, failed.ID)
+	got = waitJob(t, st, failed.ID)
+	assert.Equal(t, models.JobFailed, got.Status)
+	assert.Equal(t, errFake.Error(), got.Error)
+	assert.Nil(t, got.Result)
+}
+
+func TestSyncJobProgress(t *testing.T) {
+	st := newTestStore(t)
+	w := newTestWorker(st, newFakeClient(models.PPPoESecret{Name: "alice", Profile: "10M"}))
+	go w.handleCommands()
+	t.Cleanup(w.Stop)
+
+	var reported []int
+	job, err := SubmitJob("sync", w, func(progress func(int)) (interface{}, error) {
+		return w.ExecuteWithProgress(CmdSync, nil, JobTimeout, func(pct int) {
+			reported = append(reported, pct)
+			progress(pct)
+		})
+	})
+	require.NoError(t, err)
+
+	got := waitJob(t, st, job.ID)
+	assert.Equal(t, models.JobSucceeded, got.Status)
+	assert.Equal(t, []int{50}, reported, "reported by the worker between fetch and save")
+	assert.Equal(t, 100, got.Progress)
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+var (
+	ErrRouterNotFound = errors.New("router not found for subscriber")
+	ErrRouterOffline  = errors.New("router offline")
+)
+
+// ValidateOperation checks the fields an operation's action needs
+func ValidateOperation(op models.Operation) error {
+	switch op.Action {
+	case models.OpCreateSecret:
+		if op.Password == "" || op.Profile == "" {
+			return fmt.Errorf("%s requires password and profile", op.Action)
+		}
+	case models.OpChangeProfile:
+		if op.Profile == "" {
+			return fmt.Errorf("%s requires profile", op.Action)
+		}
+	case models.OpIsolate, models.OpUnisolate, models.OpKick:
+	default:
+		return fmt.Errorf("unknown action %q", op.Action)
+	}
+	if op.User == "" {
+		return errors.New("user is required")
+	}
+	return nil
+}
+
+// ResolveWorker picks the router a request targets. An explicit router ID
+// wins; otherwise the router the subscriber is online on, then the router it
+// is provisioned on in pppoe_users. Requests with neither fall back to router 1.
+func (p *Pool) ResolveWorker(routerID int, username string) *Worker {
+	if routerID != 0 {
+		return p.GetWorker(routerID)
+	}
+	if username == "" {
+		return p.GetWorker(1) // MVP default
+	}
+	if id, ok := p.FindActiveUser(username); ok {
+		return p.GetWorker(id)
+	}
+	if ids, err := p.Store.FindUserRouterIDs(username); err == nil && len(ids) > 0 {
+		return p.GetWorker(ids[0])
+	}
+	return nil
+}
+
+// RunOperation executes op on the subscriber's router through the usual
+// worker commands. New secrets go to router 1 unless RouterID is set.
+func (p *Pool) RunOperation(op models.Operation, timeout time.Duration) (*Worker, interface{}, error) {
+	w, err := p.planOperation(op)
+	if err != nil {
+		return w, nil, err
+	}
+
+	list := op.List
+	if list == "" {
+		list = "ISOLATED"
+	}
+
+	var res interface{}
+	switch op.Action {
+	case models.OpCreateSecret:
+		res, err = w.Execute(CmdCreateSecret, map[string]string{
+			"user": op.User, "password": op.Password, "profile": op.Profile,
+			"local_ip": op.LocalIP, "remote_ip": op.RemoteIP, "comment": op.Comment,
+		}, timeout)
+	case models.OpChangeProfile:
+		res, err = w.Execute(CmdUpdateSecret, map[string]string{"user": op.User, "profile": op.Profile}, timeout)
+	case models.OpIsolate:
+		res, err = w.Execute(CmdIsolate, map[string]string{"user": op.User, "list": list, "action": "add", "comment": op.Comment}, timeout)
+	case models.OpUnisolate:
+		res, err = w.Execute(CmdIsolate, map[string]string{"user": op.User, "list": list, "action": "remove"}, timeout)
+	case models.OpKick:
+		res, err = w.Execute(CmdKick, op.User, timeout)
+	}
+	return w, res, err
+}
+
+// planOperation validates op and resolves the worker it would run on
+func (p *Pool) planOperation(op models.Operation) (*Worker, error) {
+	if err := ValidateOperation(op); err != nil {
+		return nil, err
+	}
+	user := op.User
+	if op.Action == models.OpCreateSecret {
+		user = ""
+	}
+	w := p.ResolveWorker(op.RouterID, user)
+	if w == nil {
+		return nil, ErrRouterNotFound
+	}
+	if !w.IsOnline {
+		return w, ErrRouterOffline
+	}
+	return w, nil
+}
+
+// Retryable reports whether an operation failed because the router could not
+// be reached, as opposed to RouterOS rejecting it.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRouterOffline) || errors.Is(err, ErrWorkerBusy) || errors.Is(err, ErrTimeout)
+}
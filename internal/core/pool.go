@@ -13,17 +13,31 @@ type Pool struct {
 	Workers map[int]*Worker
 	Lock    sync.RWMutex
 	Ready   sync.WaitGroup
+	Index     *SubscriberIndex
+	Anomalies *AnomalyDetector
+	History   *SessionRecorder
+	Store     database.Store // Shared by the workers
+
+	observeMu sync.Mutex // Orders session observations against removing a worker's sessions
 }
 
 var GlobalPool *Pool
 
-func InitPool() {
+// InitPool starts a worker per router, all working against store
+func InitPool(store database.Store) {
 	GlobalPool = &Pool{
 		Workers: make(map[int]*Worker),
+		Index:     NewSubscriberIndex(),
+		Anomalies: NewAnomalyDetector(),
+		History:   NewSessionRecorder(store),
+		Store:     store,
 	}
 
-	// 1. Fetch Routers
-	routers, err := database.GetAllRouters()
+	// Jobs that were in flight when the engine stopped will never finish
+	store.FailInterruptedJobs(time.Now())
+
+	// 1. Fetch Routers (database and/or ROUTERS_FILE)
+	routers, err := LoadRouters(store)
 	if err != nil {
 		logger.Error("Failed to load routers for pool - Continuing with empty pool", zap.Error(err))
 	}
@@ -31,19 +45,53 @@ func InitPool() {
 	// 2. Spawn Workers
 	for _, r := range routers {
 		GlobalPool.Ready.Add(1) // Expect readiness signal
-		worker := NewWorker(r, &GlobalPool.Ready)
-		
-		GlobalPool.Lock.Lock()
-		GlobalPool.Workers[r.ID] = worker
-		GlobalPool.Lock.Unlock()
-
-		// Start the engine in a persistent Goroutine
-		go worker.Start()
+		GlobalPool.addWorker(r, &GlobalPool.Ready)
 	}
 
 	logger.Info("Worker Pool Initialized", zap.Int("workers", len(routers)))
 }
 
+func (p *Pool) addWorker(r models.Router, ready *sync.WaitGroup) {
+	worker := NewWorker(r, p.Store, ready)
+	worker.pool = p
+
+	p.Lock.Lock()
+	p.Workers[r.ID] = worker
+	p.Lock.Unlock()
+
+	// Start the engine in a persistent Goroutine
+	go worker.Start()
+}
+
+// Recover reconciles the engine with the database once it is back after an
+// outage: the inventory is reloaded, isolations changed in memory are written
+// back and every online router is synced again.
+func (p *Pool) Recover() {
+	logger.Info("Reconciling with database after outage")
+
+	routers, err := LoadRouters(p.Store)
+	if err != nil {
+		logger.Error("Failed to load routers after reconnect", zap.Error(err))
+		return
+	}
+	p.ApplyRouters(routers)
+
+	p.Lock.RLock()
+	workers := make([]*Worker, 0, len(p.Workers))
+	for _, w := range p.Workers {
+		workers = append(workers, w)
+	}
+	p.Lock.RUnlock()
+
+	for _, w := range workers {
+		w.persistIsolations()
+		if w.IsOnline {
+			go w.Execute(CmdSync, nil, JobTimeout)
+		}
+	}
+	logger.Info("Reconciled with database", zap.Int("routers", len(workers)))
+}
+
 func (p *Pool) WaitForReady() {
 	logger.Info("Waiting for routers to warmup...")
 	
@@ -58,7 +106,7 @@ func (p *Pool) WaitForReady() {
 	select {
 	case <-done:
 		logger.Info("All routers ready!")
-	case <-time.After(5 * time.Second):
+	case <-time.After(WarmupTimeout):
 		logger.Warn("Warmup timed out - Some routers may be effectively offline or slow")
 	}
 }
@@ -86,3 +134,46 @@ func (p *Pool) GetAllTargets() []models.ActiveUser {
 	}
 	return total
 }
+
+// FindActiveUser returns the ID of the router a subscriber is currently online on
+func (p *Pool) FindActiveUser(username string) (int, bool) {
+	p.Lock.RLock()
+	defer p.Lock.RUnlock()
+
+	for id, w := range p.Workers {
+		w.Lock.RLock()
+		for _, u := range w.ActiveUsers {
+			if u.Name == username {
+				w.Lock.RUnlock()
+				return id, true
+			}
+		}
+		w.Lock.RUnlock()
+	}
+	return 0, false
+}
+
+// observeSessions is called by a worker after every successful ActiveUsers
+// refresh, with the changes since the previous one.
+func (p *Pool) observeSessions(w *Worker, users []models.ActiveUser, events []SessionEvent) {
+	p.observeMu.Lock()
+	defer p.observeMu.Unlock()
+	if w.stopped() {
+		return // Removed from the inventory while refreshing
+	}
+	p.Index.Update(w.Router.ID, users)
+	p.Anomalies.observe(p, events)
+	p.History.record(w.Router.ID, users, events)
+}
+
+// forgetSessions drops a stopped worker's sessions from the index and the
+// anomaly findings. A refresh that was already running when it stopped either
+// finished before this or sees it stopped, so the sessions cannot come back.
+func (p *Pool) forgetSessions(w *Worker) {
+	p.observeMu.Lock()
+	defer p.observeMu.Unlock()
+	p.Index.Update(w.Router.ID, nil)
+	if p.Anomalies != nil {
+		p.Anomalies.observe(p, nil)
+	}
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Orphan policies for secrets that are on the router but not in the source
+const (
+	OrphanIgnore  = "ignore"
+	OrphanDisable = "disable"
+	OrphanRemove  = "remove"
+)
+
+// reasonOrphan marks changes to secrets that are not in the source
+const reasonOrphan = "not in source"
+
+// ReconcileMaxRemove is the percent of a router's secrets one apply may remove
+// or disable as orphans unless forced (policy.reconcile_max_remove)
+var ReconcileMaxRemove = defaults.Policy.ReconcileMaxRemove
+
+// ErrUnsafeReconcile is returned when an apply would remove or disable too many secrets
+var ErrUnsafeReconcile = errors.New("refusing to apply reconciliation")
+
+// ReconcileOptions is the payload of CmdReconcile
+type ReconcileOptions struct {
+	Mode    string                 // models.ReconcilePlan, ReconcileApply or ReconcileDrift
+	Source  string                 // "db" (pppoe_users) or "request" (Desired)
+	Orphans string                 // OrphanIgnore, OrphanDisable or OrphanRemove
+	Desired []models.DesiredSecret // Source "request" only
+	Force   bool                   // Apply past ReconcileMaxRemove
+}
+
+// PlanReconcile computes the changes that make a router's secrets match the
+// desired state. Users in managed (suspended, FUP-limited) are left alone
+// because the engine deliberately changed them. It also returns how many
+// desired secrets are already in sync.
+func PlanReconcile(desired []models.DesiredSecret, actual []models.PPPoESecret, orphans string, managed map[string]bool) ([]models.SecretChange, int) {
+	onRouter := make(map[string]models.PPPoESecret, len(actual))
+	for _, s := range actual {
+		onRouter[s.Name] = s
+	}
+
+	desired = append([]models.DesiredSecret(nil), desired...)
+	sort.Slice(desired, func(i, j int) bool { return desired[i].Username < desired[j].Username })
+
+	changes := make([]models.SecretChange, 0)
+	wanted := make(map[string]bool, len(desired))
+	inSync := 0
+	for _, d := range desired {
+		wanted[d.Username] = true
+		if managed[d.Username] {
+			continue
+		}
+
+		s, exists := onRouter[d.Username]
+		if !exists {
+			fields := map[string]string{}
+			if d.Profile != "" {
+				fields["profile"] = d.Profile
+			}
+			if d.RemoteAddress != "" {
+				fields["remote-address"] = d.RemoteAddress
+			}
+			if d.Enabled != nil && !*d.Enabled {
+				fields["disabled"] = "yes"
+			}
+			changes = append(changes, models.SecretChange{Action: models.SecretCreate, Username: d.Username, Fields: fields, Reason: "missing on router"})
+			continue
+		}
+
+		action, fields := models.SecretUpdate, map[string]string{}
+		if d.Profile != "" && d.Profile != s.Profile {
+			fields["profile"] = d.Profile
+		}
+		if d.RemoteAddress != "" && d.RemoteAddress != s.RemoteAddress {
+			fields["remote-address"] = d.RemoteAddress
+		}
+		if d.Enabled != nil && *d.Enabled == s.Disabled {
+			if *d.Enabled {
+				fields["disabled"] = "no"
+			} else {
+				fields["disabled"] = "yes"
+				action = models.SecretDisable
+			}
+		}
+		if len(fields) == 0 {
+			inSync++
+			continue
+		}
+		changes = append(changes, models.SecretChange{Action: action, Username: d.Username, Fields: fields, Reason: describeFields(fields, s)})
+	}
+
+	extra := make([]string, 0)
+	for name := range onRouter {
+		if !wanted[name] && !managed[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		switch {
+		case orphans == OrphanRemove:
+			changes = append(changes, models.SecretChange{Action: models.SecretRemove, Username: name, Reason: reasonOrphan})
+		case orphans == OrphanDisable && !onRouter[name].Disabled:
+			changes = append(changes, models.SecretChange{Action: models.SecretDisable, Username: name, Fields: map[string]string{"disabled": "yes"}, Reason: reasonOrphan})
+		}
+	}
+
+	return changes, inSync
+}
+
+func describeFields(fields map[string]string, s models.PPPoESecret) string {
+	current := map[string]string{"profile": s.Profile, "remote-address": s.RemoteAddress, "disabled": "no"}
+	if s.Disabled {
+		current["disabled"] = "yes"
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	reason := ""
+	for _, k := range keys {
+		if reason != "" {
+			reason += ", "
+		}
+		reason += fmt.Sprintf("%s %q -> %q", k, current[k], fields[k])
+	}
+	return reason
+}
+
+// checkOrphanChanges refuses to remove or disable secrets because an empty
+// source left them out, or more of them than ReconcileMaxRemove allows
+// without force: an empty table or a broken billing export must not wipe a router.
+func checkOrphanChanges(changes []models.SecretChange, desired, actual int, force bool) error {
+	orphaned := 0
+	for _, c := range changes {
+		if c.Reason == reasonOrphan {
+			orphaned++
+		}
+	}
+	if orphaned == 0 {
+		return nil
+	}
+	if desired == 0 {
+		return fmt.Errorf("%w: the source is empty and %d secrets would be removed or disabled", ErrUnsafeReconcile, orphaned)
+	}
+	if limit := float64(actual) * ReconcileMaxRemove / 100; !force && float64(orphaned) > limit {
+		return fmt.Errorf("%w: %d of %d secrets would be removed or disabled, over the %g%% limit; set force to apply",
+			ErrUnsafeReconcile, orphaned, actual, ReconcileMaxRemove)
+	}
+	return nil
+}
+
+// reconcile runs CmdReconcile. It must only be called from the command loop.
+func (w *Worker) reconcile(opts ReconcileOptions, progress func(int)) (*models.ReconcileResult, error) {
+	result := &models.ReconcileResult{RouterID: w.Router.ID, Mode: opts.Mode, Source: opts.Source, StartedAt: time.Now()}
+
+	desired := opts.Desired
+	if opts.Source == "db" {
+		users, err := w.store.GetUsersByRouter(w.Router.ID)
+		if err != nil {
+			return nil, err
+		}
+		desired = desiredFromUsers(users)
+	}
+
+	actual, err := w.Client.GetAllSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	managed, err := w.managedUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	progress(10) // Both sides read; applying takes the rest
+	changes, inSync := PlanReconcile(desired, actual, opts.Orphans, managed)
+	result.Desired, result.Actual, result.InSync, result.Managed = len(desired), len(actual), inSync, len(managed)
+	result.Changes = changes
+
+	if opts.Mode == models.ReconcileApply {
+		if err := checkOrphanChanges(changes, len(desired), len(actual), opts.Force); err != nil {
+			return nil, err
+		}
+		passwords := make(map[string]string, len(desired))
+		for _, d := range desired {
+			passwords[d.Username] = d.Password
+		}
+		for i := range result.Changes {
+			w.applySecretChange(&result.Changes[i], passwords[result.Changes[i].Username])
+			progress(10 + 90*(i+1)/len(result.Changes))
+		}
+	}
+
+	result.Counts = make(map[string]int)
+	for _, c := range result.Changes {
+		result.Counts[c.Action]++
+		if c.Status != "" {
+			result.Counts[c.Status]++
+		}
+	}
+	result.FinishedAt = time.Now()
+
+	w.Lock.Lock()
+	w.lastReconcile = result
+	w.Lock.Unlock()
+
+	logger.Info("Reconciled router", zap.String("router", w.Router.Name), zap.String("mode", opts.Mode),
+		zap.Int("changes", len(result.Changes)), zap.Int("in_sync", inSync))
+	if opts.Mode == models.ReconcileDrift && len(result.Changes) > 0 {
+		SendWebhook("router.drift", w.Router.ID, w.Router.Host, result)
+	}
+	return result, nil
+}
+
+// desiredFromUsers is the desired state recorded in pppoe_users
+func desiredFromUsers(users map[string]database.DBUser) []models.DesiredSecret {
+	desired := make([]models.DesiredSecret, 0, len(users))
+	for _, u := range users {
+		enabled := u.IsEnabled
+		desired = append(desired, models.DesiredSecret{Username: u.Username, Profile: u.Profile, RemoteAddress: u.RemoteAddress, Enabled: &enabled})
+	}
+	return desired
+}
+
+// managedUsers returns the users whose secrets the engine changed on purpose
+func (w *Worker) managedUsers() (map[string]bool, error) {
+	suspensions, err := w.store.GetSuspensionsByRouter(w.Router.ID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := w.store.GetFUPStates()
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[string]bool, len(suspensions))
+	for name := range suspensions {
+		managed[name] = true
+	}
+	for _, s := range states {
+		if s.RouterID == w.Router.ID && s.Action == models.FUPThrottle {
+			managed[s.Username] = true
+		}
+	}
+	return managed, nil
+}
+
+func (w *Worker) applySecretChange(c *models.SecretChange, password string) {
+	var err error
+	switch c.Action {
+	case models.SecretCreate:
+		if password == "" {
+			c.Status, c.Error = "skipped", "password unknown; send the user with a password in the request"
+			return
+		}
+		_, err = w.Client.EnsureSecret(c.Username, password, c.Fields["profile"], "", c.Fields["remote-address"], "")
+		if err == nil && c.Fields["disabled"] == "yes" {
+			_, err = w.Client.EnsureSecretDisabled(c.Username, true)
+		}
+	case models.SecretUpdate:
+		err = w.Client.SetSecretFields(c.Username, c.Fields)
+	case models.SecretDisable:
+		err = w.Client.SetSecretFields(c.Username, c.Fields)
+		if err == nil {
+			// Disabling does not end the current session
+			_, err = w.Client.KickUser(c.Username)
+		}
+	case models.SecretRemove:
+		_, err = w.Client.EnsureSecretAbsent(c.Username)
+		if err == nil {
+			_, err = w.Client.KickUser(c.Username)
+		}
+	}
+
+	if err != nil {
+		c.Status, c.Error = "failed", err.Error()
+		return
+	}
+	c.Status = "applied"
+}
+
+// LastReconcile returns the result of the most recent reconciliation, if any
+func (w *Worker) LastReconcile() *models.ReconcileResult {
+	w.Lock.RLock()
+	defer w.Lock.RUnlock()
+	return w.lastReconcile
+}
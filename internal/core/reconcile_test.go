@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	yes, no := true, false
+	desired := []models.DesiredSecret{
+		{Username: "alice", Profile: "10M", Enabled: &yes},                          // in sync
+		{Username: "bob", Profile: "20M", RemoteAddress: "10.0.0.9", Enabled: &yes}, // profile + address drift
+		{Username: "carol", Profile: "10M", Enabled: &no},                           // should be disabled
+		{Username: "dave", Profile: "10M", Password: "x"},                           // missing on router
+		{Username: "erin", Profile: "10M", Enabled: &yes},                           // suspended, managed
+	}
+	actual := []models.PPPoESecret{
+		{Name: "alice", Profile: "10M"},
+		{Name: "bob", Profile: "10M", RemoteAddress: "10.0.0.5"},
+		{Name: "carol", Profile: "10M"},
+		{Name: "erin", Profile: "isolir", Disabled: true},
+		{Name: "frank", Profile: "10M"},
+		{Name: "gina", Profile: "10M", Disabled: true},
+	}
+	managed := map[string]bool{"erin": true}
+
+	changes, inSync := PlanReconcile(desired, actual, OrphanDisable, managed)
+	assert.Equal(t, 1, inSync)
+	assert.Equal(t, []models.SecretChange{
+		{Action: models.SecretUpdate, Username: "bob", Fields: map[string]string{"profile": "20M", "remote-address": "10.0.0.9"},
+			Reason: `profile "10M" -> "20M", remote-address "10.0.0.5" -> "10.0.0.9"`},
+		{Action: models.SecretDisable, Username: "carol", Fields: map[string]string{"disabled": "yes"}, Reason: `disabled "no" -> "yes"`},
+		{Action: models.SecretCreate, Username: "dave", Fields: map[string]string{"profile": "10M"}, Reason: "missing on router"},
+		// gina is already disabled
+		{Action: models.SecretDisable, Username: "frank", Fields: map[string]string{"disabled": "yes"}, Reason: "not in source"},
+	}, changes)
+
+	changes, _ = PlanReconcile(desired, actual, OrphanRemove, managed)
+	assert.Equal(t, models.SecretRemove, changes[len(changes)-1].Action)
+	assert.Equal(t, "gina", changes[len(changes)-1].Username)
+
+	changes, _ = PlanReconcile(desired, actual, OrphanIgnore, managed)
+	assert.Len(t, changes, 3)
+}
+
+func TestReconcileApply(t *testing.T) {
+	secrets := []models.PPPoESecret{
+		{Name: "alice", Profile: "10M"},
+		{Name: "bob", Profile: "10M"},
+		{Name: "erin", Profile: "isolir"},
+		{Name: "frank", Profile: "10M"},
+	}
+	for i := 1; i <= 8; i++ {
+		secrets = append(secrets, models.PPPoESecret{Name: fmt.Sprintf("user%02d", i), Profile: "10M"})
+	}
+	apply := func(desired []models.DesiredSecret, force bool) (*fakeClient, *models.ReconcileResult, error) {
+		st := newTestStore(t)
+		require.NoError(t, st.SaveSuspension(models.Suspension{
+			Username: "erin", RouterID: 1, Strategy: models.SuspendIsolir, Profile: "isolir", PreviousProfile: "10M", CreatedAt: time.Now(),
+		}))
+		client := newFakeClient(secrets...)
+		res, err := newTestWorker(st, client).reconcile(ReconcileOptions{
+			Mode: models.ReconcileApply, Source: "request", Orphans: OrphanRemove, Desired: desired, Force: force,
+		}, func(int) {})
+		return client, res, err
+	}
+
+	t.Run("applies the plan", func(t *testing.T) {
+		desired := []models.DesiredSecret{
+			{Username: "carol", Profile: "10M", Password: "secret"},
+			{Username: "alice", Profile: "20M"},
+			{Username: "bob", Profile: "10M"},
+		}
+		for i := 1; i <= 8; i++ {
+			desired = append(desired, models.DesiredSecret{Username: fmt.Sprintf("user%02d", i)})
+		}
+		order := append([]models.DesiredSecret(nil), desired...)
+
+		client, res, err := apply(desired, false)
+		require.NoError(t, err)
+		assert.Equal(t, order, desired, "the caller's slice is not reordered")
+		assert.Equal(t, map[string]int{models.SecretCreate: 1, models.SecretUpdate: 1, models.SecretRemove: 1, "applied": 3}, res.Counts)
+		assert.Equal(t, "20M", client.secret("alice").Profile)
+		assert.Equal(t, "10M", client.secret("carol").Profile)
+		assert.Equal(t, "isolir", client.secret("erin").Profile, "suspended users are left alone")
+		assert.Equal(t, []string{"frank"}, client.removed)
+		assert.Equal(t, []string{"frank"}, client.kicked)
+	})
+
+	t.Run("refuses an empty source", func(t *testing.T) {
+		client, _, err := apply(nil, true)
+		assert.ErrorIs(t, err, ErrUnsafeReconcile)
+		assert.Empty(t, client.removed)
+	})
+
+	t.Run("caps removals unless forced", func(t *testing.T) {
+		desired := []models.DesiredSecret{{Username: "alice"}, {Username: "bob"}}
+
+		client, _, err := apply(desired, false)
+		assert.ErrorIs(t, err, ErrUnsafeReconcile)
+		assert.Empty(t, client.removed)
+
+		client, res, err := apply(desired, true)
+		require.NoError(t, err)
+		assert.Equal(t, 9, res.Counts[models.SecretRemove])
+		assert.Len(t, client.removed, 9)
+	})
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"time"
+
+	"skynet-net-engine-api/internal/database"
+	"skynet-net-engine-api/internal/inventory"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Router sources (ROUTERS_SOURCE)
+const (
+	RouterSourceDB    = "db"    // routers table only
+	RouterSourceFile  = "file"  // ROUTERS_FILE only; the database is not needed for the inventory
+	RouterSourceMerge = "merge" // both, file entries win on the same ID
+)
+
+// RoutersFile is a routers.yaml/routers.json inventory (routers.file). It is
+// reloaded when it changes. RoutersSource says how it combines with the
+// database: "merge" (default when a file is set), "file" or "db".
+var (
+	RoutersFile   string
+	RoutersSource = RouterSourceDB
+)
+
+// routersFileInterval is how often RoutersFile is checked for changes
+const routersFileInterval = 5 * time.Second
+
+// LoadRouters returns the router inventory from the configured source. In
+// merge mode a database error is returned along with the file's routers.
+func LoadRouters(store database.Store) ([]models.Router, error) {
+	if RoutersSource == RouterSourceDB || RoutersFile == "" {
+		return store.GetAllRouters()
+	}
+
+	fromFile, err := inventory.Load(RoutersFile)
+	if err != nil {
+		logger.Error("Failed to load router file", zap.String("path", RoutersFile), zap.Error(err))
+		return nil, err
+	}
+	if RoutersSource == RouterSourceFile {
+		return fromFile, nil
+	}
+
+	fromDB, err := store.GetAllRouters()
+	if err != nil {
+		// Still return the file's routers so the engine can start; callers
+		// that reconcile a running pool must not treat them as the full inventory
+		logger.Warn("Database routers unavailable, router file only", zap.Error(err))
+		return fromFile, err
+	}
+	return inventory.Merge(fromDB, fromFile), nil
+}
+
+// ApplyRouters makes the pool match an inventory: new routers get workers,
+// removed ones are stopped and changed ones (address or credentials) restarted.
+func (p *Pool) ApplyRouters(routers []models.Router) {
+	wanted := make(map[int]models.Router, len(routers))
+	for _, r := range routers {
+		wanted[r.ID] = r
+	}
+
+	p.Lock.Lock()
+	var stale []*Worker
+	for id, w := range p.Workers {
+		if r, ok := wanted[id]; !ok || r != w.Router {
+			stale = append(stale, w)
+			delete(p.Workers, id)
+		}
+	}
+	p.Lock.Unlock()
+
+	for _, w := range stale {
+		w.Stop()
+		p.forgetSessions(w)
+	}
+
+	added := 0
+	for _, r := range routers {
+		if p.GetWorker(r.ID) == nil {
+			p.addWorker(r, nil)
+			added++
+		}
+	}
+	if added > 0 || len(stale) > 0 {
+		logger.Info("Router inventory applied", zap.Int("started", added), zap.Int("stopped", len(stale)), zap.Int("routers", len(routers)))
+	}
+}
+
+// WatchRouters reloads ROUTERS_FILE whenever it changes. An invalid file is
+// logged and ignored, leaving the running workers alone.
+func (p *Pool) WatchRouters() {
+	if RoutersFile == "" || RoutersSource == RouterSourceDB {
+		return
+	}
+	inventory.Watch(RoutersFile, routersFileInterval, func() {
+		logger.Info("Router file changed, reloading", zap.String("path", RoutersFile))
+		routers, err := LoadRouters(p.Store)
+		if err != nil {
+			return
+		}
+		p.ApplyRouters(routers)
+	})
+}
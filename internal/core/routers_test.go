@@ -0,0 +1,61 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRouters(t *testing.T) {
+	st := newTestStore(t)
+	p := &Pool{Workers: map[int]*Worker{}, Index: NewSubscriberIndex(), Anomalies: NewAnomalyDetector(), History: newSessionRecorder(st, 10), Store: st}
+	t.Cleanup(func() {
+		for _, w := range p.Workers {
+			w.Stop()
+		}
+	})
+
+	// Nothing listens on port 1, so workers keep retrying until stopped
+	hq := models.Router{ID: 1, Name: "hq", Host: "127.0.0.1", Port: 1}
+	edge := models.Router{ID: 2, Name: "edge", Host: "127.0.0.1", Port: 1}
+	p.ApplyRouters([]models.Router{hq, edge})
+	require.Len(t, p.Workers, 2)
+	oldHQ, oldEdge := p.GetWorker(1), p.GetWorker(2)
+
+	alice := func(routerID int) []models.ActiveUser {
+		return []models.ActiveUser{{Name: "alice", Address: "10.0.0.5", CallerID: "AA:BB:CC:DD:EE:FF", RouterID: routerID}}
+	}
+	p.observeSessions(oldHQ, alice(1), nil)
+	p.observeSessions(oldEdge, alice(2), nil)
+	require.Len(t, p.Index.ByName("alice"), 2)
+	require.Len(t, p.Anomalies.Report().DuplicateSessions, 1)
+
+	// Remove hq, move edge, add branch
+	edge.Host = "127.0.0.2"
+	branch := models.Router{ID: 3, Name: "branch", Host: "127.0.0.1", Port: 1}
+	p.ApplyRouters([]models.Router{edge, branch})
+
+	require.Len(t, p.Workers, 2)
+	assert.Nil(t, p.GetWorker(1))
+	require.NotNil(t, p.GetWorker(2))
+	assert.NotSame(t, oldEdge, p.GetWorker(2))
+	assert.Equal(t, "127.0.0.2", p.GetWorker(2).Router.Host)
+	assert.NotNil(t, p.GetWorker(3))
+	assert.True(t, oldHQ.stopped())
+	assert.True(t, oldEdge.stopped())
+
+	assert.Empty(t, p.Index.ByName("alice"))
+	assert.Empty(t, p.Anomalies.Report().DuplicateSessions)
+
+	// A refresh of a removed worker that finishes late changes nothing
+	p.observeSessions(oldHQ, alice(1), nil)
+	assert.Empty(t, p.Index.ByName("alice"))
+
+	// Applying the same inventory again keeps the running workers
+	current := p.GetWorker(2)
+	p.ApplyRouters([]models.Router{edge, branch})
+	assert.Same(t, current, p.GetWorker(2))
+}
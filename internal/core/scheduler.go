@@ -0,0 +1,122 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	schedulerInterval   = 30 * time.Second
+	scheduleRetryDelay  = time.Minute // Wait before retrying a job whose router was unreachable
+	scheduleMaxAttempts = 30
+)
+
+// StartScheduler runs due scheduled jobs in the background
+func (p *Pool) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			p.RunDueJobs()
+		}
+	}()
+}
+
+// RunDueJobs runs every pending job whose time has come. Jobs run in
+// parallel but the call waits for all of them, so a job is never picked up
+// twice.
+func (p *Pool) RunDueJobs() {
+	now := time.Now()
+	jobs, err := p.Store.GetDueJobs(now)
+	if err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j models.ScheduledJob) {
+			defer wg.Done()
+			p.runScheduledJob(j, now)
+		}(j)
+	}
+	wg.Wait()
+}
+
+func (p *Pool) runScheduledJob(j models.ScheduledJob, now time.Time) {
+	w, _, err := p.RunOperation(j.Operation, time.Minute)
+	j.LastRunAt = &now
+
+	switch {
+	case err == nil:
+		j.Runs++
+		j.Attempts = 0
+		j.LastError = ""
+		scheduleNextRun(&j, now)
+	case Retryable(err) && j.Attempts+1 < scheduleMaxAttempts:
+		// Router unreachable; keep the job and try again shortly
+		j.Attempts++
+		j.LastError = err.Error()
+		next := now.Add(scheduleRetryDelay)
+		j.NextRunAt = &next
+	default:
+		j.Attempts++
+		j.LastError = err.Error()
+		if j.Cron != "" {
+			// A recurring job skips this occurrence and keeps its schedule
+			j.Attempts = 0
+			scheduleNextRun(&j, now)
+		} else {
+			j.Status = models.ScheduleFailed
+			j.NextRunAt = nil
+		}
+	}
+
+	if ok, _ := p.Store.UpdateScheduledJob(j); !ok {
+		return // Cancelled meanwhile
+	}
+
+	routerID, host := j.RouterID, ""
+	if w != nil {
+		routerID, host = w.Router.ID, w.Router.Host
+	}
+	if err != nil {
+		logger.Warn("Scheduled job failed", zap.Int64("id", j.ID), zap.String("action", j.Action), zap.String("user", j.User), zap.Error(err))
+		if j.Attempts == 0 || j.Status == models.ScheduleFailed {
+			SendWebhook("schedule.failed", routerID, host, j)
+		}
+		return
+	}
+	logger.Info("Scheduled job executed", zap.Int64("id", j.ID), zap.String("action", j.Action), zap.String("user", j.User))
+	SendWebhook("schedule.executed", routerID, host, j)
+}
+
+// scheduleNextRun moves a recurring job to its next occurrence and finishes a one-shot job
+func scheduleNextRun(j *models.ScheduledJob, now time.Time) {
+	if j.Cron == "" {
+		j.Status = models.ScheduleDone
+		j.NextRunAt = nil
+		return
+	}
+	cron, err := ParseCron(j.Cron)
+	if err != nil {
+		j.Status = models.ScheduleFailed
+		j.LastError = err.Error()
+		j.NextRunAt = nil
+		return
+	}
+	next := cron.Next(now)
+	if next.IsZero() {
+		j.Status = models.ScheduleFailed
+		j.LastError = "cron schedule never fires"
+		j.NextRunAt = nil
+		return
+	}
+	j.NextRunAt = &next
+}
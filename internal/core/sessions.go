@@ -0,0 +1,49 @@
+package core
+
+import "skynet-net-engine-api/internal/models"
+
+type SessionEventType string
+
+const (
+	SessionConnected    SessionEventType = "connected"
+	SessionDisconnected SessionEventType = "disconnected"
+)
+
+// SessionEvent is a single change between two ActiveUsers snapshots
+type SessionEvent struct {
+	Type SessionEventType
+	User models.ActiveUser
+}
+
+// sessionKey identifies a PPP session. A reconnect with a new IP or MAC
+// shows up as a disconnect of the old session plus a connect of the new one.
+func sessionKey(u models.ActiveUser) string {
+	return u.Name + "|" + u.Address + "|" + u.CallerID
+}
+
+// diffSessions compares two ActiveUsers snapshots of the same router.
+// Disconnects are listed before connects so consumers can release state
+// (e.g. address-list entries) before re-acquiring it.
+func diffSessions(prev, next []models.ActiveUser) []SessionEvent {
+	before := make(map[string]models.ActiveUser, len(prev))
+	for _, u := range prev {
+		before[sessionKey(u)] = u
+	}
+	after := make(map[string]models.ActiveUser, len(next))
+	for _, u := range next {
+		after[sessionKey(u)] = u
+	}
+
+	events := make([]SessionEvent, 0)
+	for _, u := range prev {
+		if _, ok := after[sessionKey(u)]; !ok {
+			events = append(events, SessionEvent{Type: SessionDisconnected, User: u})
+		}
+	}
+	for _, u := range next {
+		if _, ok := before[sessionKey(u)]; !ok {
+			events = append(events, SessionEvent{Type: SessionConnected, User: u})
+		}
+	}
+	return events
+}
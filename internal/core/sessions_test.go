@@ -0,0 +1,39 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSessions(t *testing.T) {
+	prev := []models.ActiveUser{
+		{Name: "alice", Address: "10.0.0.2", CallerID: "AA:AA"},
+		{Name: "bob", Address: "10.0.0.3", CallerID: "BB:BB"},
+	}
+	next := []models.ActiveUser{
+		{Name: "alice", Address: "10.0.0.2", CallerID: "AA:AA", Uptime: "5m"}, // unchanged
+		{Name: "bob", Address: "10.0.0.9", CallerID: "BB:BB"},                 // reconnected with new IP
+		{Name: "carol", Address: "10.0.0.4", CallerID: "CC:CC"},               // new
+	}
+
+	events := diffSessions(prev, next)
+
+	// Disconnects come first so state is released before it is re-acquired
+	assert.Equal(t, []SessionEvent{
+		{Type: SessionDisconnected, User: prev[1]},
+		{Type: SessionConnected, User: next[1]},
+		{Type: SessionConnected, User: next[2]},
+	}, events)
+}
+
+func TestDiffSessionsFromEmpty(t *testing.T) {
+	next := []models.ActiveUser{{Name: "alice", Address: "10.0.0.2"}}
+
+	events := diffSessions(nil, next)
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, SessionConnected, events[0].Type)
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// suspendUser cuts a subscriber off using the requested strategy. The
+// suspension is persisted before the router is touched so that resume can
+// always restore the previous profile.
+func (w *Worker) suspendUser(payload map[string]string) (interface{}, error) {
+	username := payload["user"]
+
+	existing, err := w.store.GetSuspension(username, w.Router.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil // Already suspended
+	}
+
+	secret, err := w.Client.GetSecret(username)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	s := models.Suspension{
+		Username:         username,
+		RouterID:         w.Router.ID,
+		Strategy:         payload["strategy"],
+		PreviousProfile:  secret.Profile,
+		PreviousDisabled: secret.Disabled,
+		Comment:          payload["comment"],
+		CreatedAt:        time.Now(),
+	}
+	if s.Strategy == models.SuspendIsolir {
+		s.Profile = payload["profile"]
+	}
+
+	if err := w.store.SaveSuspension(s); err != nil {
+		return nil, fmt.Errorf("failed to record suspension: %w", err)
+	}
+
+	if err := w.applySuspension(s); err != nil {
+		// The change may have reached the router before failing: put the secret
+		// back before forgetting how it was, or keep the row so resume still can
+		if errRestore := w.restoreSecret(s); errRestore != nil {
+			logger.Error("Suspension partially applied", zap.String("router", w.Router.Name), zap.String("user", username), zap.Error(err), zap.NamedError("restore_error", errRestore))
+			return nil, fmt.Errorf("suspension partially applied, resume to restore the previous profile: %w", err)
+		}
+		if errDB := w.store.DeleteSuspension(username, w.Router.ID); errDB != nil {
+			logger.Error("Failed to remove unapplied suspension", zap.String("user", username), zap.Error(errDB))
+		}
+		return nil, err
+	}
+
+	logger.Info("User suspended", zap.String("router", w.Router.Name), zap.String("user", username), zap.String("strategy", s.Strategy))
+	return &s, nil
+}
+
+func (w *Worker) applySuspension(s models.Suspension) error {
+	switch s.Strategy {
+	case models.SuspendDisable:
+		if _, err := w.Client.EnsureSecretDisabled(s.Username, true); err != nil {
+			return err
+		}
+	case models.SuspendIsolir:
+		if _, err := w.Client.EnsureSecretProfile(s.Username, s.Profile); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown suspend strategy %q", s.Strategy)
+	}
+
+	// Kick so the change takes effect now rather than on the next reconnect
+	w.kick(s.Username)
+	return nil
+}
+
+// restoreSecret puts back the profile and disabled flag a suspension replaced
+func (w *Worker) restoreSecret(s models.Suspension) error {
+	if s.Strategy == models.SuspendIsolir {
+		changed, err := w.Client.EnsureSecretProfile(s.Username, s.PreviousProfile)
+		if err != nil {
+			return err
+		}
+		if changed {
+			// Drop the captive session so the user reconnects on the real profile
+			w.kick(s.Username)
+		}
+	}
+	_, err := w.Client.EnsureSecretDisabled(s.Username, s.PreviousDisabled)
+	return err
+}
+
+// kick drops a user's sessions. A failure is only logged: the profile change
+// itself succeeded and applies on the next reconnect.
+func (w *Worker) kick(username string) {
+	if _, err := w.Client.KickUser(username); err != nil {
+		logger.Warn("Failed to kick user", zap.String("router", w.Router.Name), zap.String("user", username), zap.Error(err))
+	}
+}
+
+// resumeUser restores the secret exactly as it was before the suspension
+func (w *Worker) resumeUser(username string) (interface{}, error) {
+	s, err := w.store.GetSuspension(username, w.Router.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("user is not suspended")
+	}
+
+	if err := w.restoreSecret(*s); err != nil {
+		return nil, err
+	}
+
+	if err := w.store.DeleteSuspension(username, w.Router.ID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("User resumed", zap.String("router", w.Router.Name), zap.String("user", username), zap.String("profile", s.PreviousProfile))
+	return s, nil
+}
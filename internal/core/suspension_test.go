@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isolir(user string) map[string]string {
+	return map[string]string{"user": user, "strategy": models.SuspendIsolir, "profile": "isolir"}
+}
+
+func TestSuspendResumeRoundTrip(t *testing.T) {
+	st := newTestStore(t)
+	client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "10M"})
+	w := newTestWorker(st, client)
+
+	_, err := w.suspendUser(isolir("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "isolir", client.secret("alice").Profile)
+	assert.Equal(t, []string{"alice"}, client.kicked)
+
+	_, err = w.resumeUser("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "10M", client.secret("alice").Profile)
+	s, err := st.GetSuspension("alice", 1)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = w.suspendUser(map[string]string{"user": "alice", "strategy": models.SuspendDisable})
+	require.NoError(t, err)
+	assert.True(t, client.secret("alice").Disabled)
+	_, err = w.resumeUser("alice")
+	require.NoError(t, err)
+	assert.False(t, client.secret("alice").Disabled)
+}
+
+func TestSuspendKickFailureIsNotFatal(t *testing.T) {
+	st := newTestStore(t)
+	client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "10M"})
+	client.fail["KickUser"] = errFake
+	w := newTestWorker(st, client)
+
+	_, err := w.suspendUser(isolir("alice"))
+	require.NoError(t, err)
+	_, err = w.resumeUser("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "10M", client.secret("alice").Profile)
+}
+
+func TestSuspendFailureMidway(t *testing.T) {
+	st := newTestStore(t)
+	client := newFakeClient(models.PPPoESecret{Name: "alice", Profile: "10M"})
+	w := newTestWorker(st, client)
+
+	// The profile changed on the router but the reply was lost: it is rolled back
+	client.failAfter["EnsureSecretProfile"] = errFake
+	_, err := w.suspendUser(isolir("alice"))
+	require.Error(t, err)
+	assert.Equal(t, "10M", client.secret("alice").Profile)
+	s, err := st.GetSuspension("alice", 1)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	// The rollback fails too: the row is kept so resume can still restore
+	client.failAfter["EnsureSecretProfile"] = errFake
+	client.fail["EnsureSecretDisabled"] = errFake
+	_, err = w.suspendUser(isolir("alice"))
+	require.ErrorContains(t, err, "partially applied")
+	s, err = st.GetSuspension("alice", 1)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.Equal(t, "10M", s.PreviousProfile)
+
+	delete(client.fail, "EnsureSecretDisabled")
+	_, err = w.resumeUser("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "10M", client.secret("alice").Profile)
+}
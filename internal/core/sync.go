@@ -0,0 +1,5 @@
+package core
+
+// SyncDeletePolicy is what a sync does with pppoe_users rows whose secret is
+// gone from the router (policy.sync_delete_policy: "mark" or "delete")
+var SyncDeletePolicy = defaults.Policy.SyncDeletePolicy
@@ -1,5 +1,7 @@
 package core
 
+import "errors"
+
 type CommandType string
 
 const (
@@ -12,11 +14,44 @@ const (
 	CmdGetTraffic   CommandType = "GET_TRAFFIC"
 	CmdRefreshMetrics CommandType = "REFRESH_METRICS"
 	CmdBackup       CommandType = "BACKUP"
+	CmdSuspend      CommandType = "SUSPEND"
+	CmdResume       CommandType = "RESUME"
+	CmdReconcile    CommandType = "RECONCILE"
+	CmdGetSecrets   CommandType = "GET_SECRETS"
 )
 
 type Command struct {
-	Type    CommandType
-	Payload interface{}
-	Result  chan interface{}
-	Error   chan error
+	Type     CommandType
+	Payload  interface{}
+	Result   chan interface{}
+	Error    chan error
+	Progress func(pct int) // Optional; long commands report how far along they are
+}
+
+var (
+	ErrWorkerBusy = errors.New("worker busy or offline")
+	ErrTimeout    = errors.New("timeout waiting for router")
+)
+
+// Change is the result of a state-checked router mutation
+type Change struct {
+	Changed bool `json:"changed"`
+}
+
+// progress reports how far along the command is, in percent
+func (c Command) progress(pct int) {
+	if c.Progress != nil {
+		c.Progress(pct)
+	}
+}
+
+// reply delivers the outcome of a command. Both channels are optional;
+// the result is only sent when the command succeeded.
+func (c Command) reply(result interface{}, err error) {
+	if c.Error != nil {
+		c.Error <- err
+	}
+	if c.Result != nil && err == nil {
+		c.Result <- result
+	}
 }
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// usageInterval is how often queue byte counters are sampled
+const usageInterval = time.Minute
+
+// collectUsage samples the queue byte counters and stores what was consumed
+// since the previous sample in the hourly usage table. The first sample after
+// start only sets the baseline.
+func (w *Worker) collectUsage() {
+	if time.Since(w.usageCheckedAt) < usageInterval {
+		return
+	}
+	now := time.Now()
+	w.usageCheckedAt = now
+
+	counters, err := w.Client.GetQueueCounters()
+	if err != nil {
+		logger.Error("Failed to fetch queue counters", zap.String("host", w.Router.Host), zap.Error(err))
+		return
+	}
+
+	deltas := usageDeltas(w.usageCounters, counters, w.usageResets)
+	w.usageCounters = counters
+	w.usageResets = make(map[string]bool)
+
+	if len(deltas) > 0 {
+		go w.store.AddUsage(w.Router.ID, now.Truncate(time.Hour), deltas)
+	}
+}
+
+// usageDeltas turns two counter snapshots into consumed bytes. Counters that
+// went backwards, queues that are new, and users that reconnected in between
+// (their queue was re-created) count from zero.
+func usageDeltas(prev, cur map[string]models.ByteCounters, reset map[string]bool) []models.ByteCounters {
+	if prev == nil {
+		return nil
+	}
+
+	deltas := make([]models.ByteCounters, 0)
+	for name, c := range cur {
+		p, seen := prev[name]
+		d := c
+		if seen && !reset[name] && c.RX >= p.RX && c.TX >= p.TX {
+			d = models.ByteCounters{Name: name, RX: c.RX - p.RX, TX: c.TX - p.TX}
+		}
+		if d.RX > 0 || d.TX > 0 {
+			deltas = append(deltas, d)
+		}
+	}
+	return deltas
+}
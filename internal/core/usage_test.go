@@ -0,0 +1,52 @@
+package core
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageDeltas(t *testing.T) {
+	prev := map[string]models.ByteCounters{
+		"andi":  {Name: "andi", RX: 100, TX: 1000},
+		"budi":  {Name: "budi", RX: 500, TX: 5000},
+		"citra": {Name: "citra", RX: 10, TX: 10},
+		"dodi":  {Name: "dodi", RX: 10, TX: 10},
+	}
+	cur := map[string]models.ByteCounters{
+		"andi":  {Name: "andi", RX: 150, TX: 1600}, // normal growth
+		"budi":  {Name: "budi", RX: 20, TX: 30},    // counter reset
+		"citra": {Name: "citra", RX: 40, TX: 50},   // reconnected between samples
+		"dodi":  {Name: "dodi", RX: 10, TX: 10},    // idle
+		"eko":   {Name: "eko", RX: 7, TX: 8},       // new queue
+	}
+
+	deltas := usageDeltas(prev, cur, map[string]bool{"citra": true})
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Name < deltas[j].Name })
+
+	assert.Equal(t, []models.ByteCounters{
+		{Name: "andi", RX: 50, TX: 600},
+		{Name: "budi", RX: 20, TX: 30},
+		{Name: "citra", RX: 40, TX: 50},
+		{Name: "eko", RX: 7, TX: 8},
+	}, deltas)
+}
+
+func TestUsageDeltasBaseline(t *testing.T) {
+	// Nothing is known about usage before the first sample
+	assert.Nil(t, usageDeltas(nil, map[string]models.ByteCounters{"andi": {Name: "andi", RX: 1}}, nil))
+}
+
+func TestCycleStart(t *testing.T) {
+	defer func(day int) { FUPCycleDay = day }(FUPCycleDay)
+	FUPCycleDay = 15
+
+	// Before the cycle day the cycle started last month
+	assert.Equal(t, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), CycleStart(time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), CycleStart(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), CycleStart(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
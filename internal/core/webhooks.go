@@ -18,10 +18,15 @@ type WebhookPayload struct {
 	Timestamp string     `json:"timestamp"`
 }
 
-// SendWebhook dispatches events to a configured URL (Mocked for now)
+// WebhookURL receives every event (webhook.url); empty disables webhooks
+var WebhookURL = defaults.Webhook.URL
+
+// SendWebhook dispatches events to WebhookURL
 func SendWebhook(event string, routerID int, host string, data interface{}) {
-	// In a real app, load this from config
-	targetURL := "http://localhost:8000/api/webhooks/net-engine" // Example Laravel endpoint
+	targetURL := WebhookURL
+	if targetURL == "" {
+		return
+	}
 
 	payload := WebhookPayload{
 		Event:     event,
@@ -13,24 +13,50 @@ import (
 type Worker struct {
 	Router   models.Router
 	CmdChan  chan Command
-	Client   *mikrotik.Client
+	Client   RouterClient
 	IsOnline bool
+	store    database.Store // The pool's store
 	
 	// Synchronization
-	once sync.Once
-	wg   *sync.WaitGroup
+	once     sync.Once
+	wg       *sync.WaitGroup
+	pool     *Pool
+	stop     chan struct{} // closed by Stop
+	stopOnce sync.Once
 
 	// Cache
 	ActiveUsers    []models.ActiveUser
 	SystemResource *models.SystemResource
+	Isolations     map[string]*models.Isolation // keyed by isolationKey
+	AddressLists   []models.AddressListEntry    // entries of IsolationLists
+	IPPools        []models.IPPoolUsage
 	Lock           sync.RWMutex
+
+	poolsCheckedAt time.Time
+	poolAlerts     map[string]bool // pools currently over PoolAlertThreshold
+
+	usageCheckedAt time.Time
+	usageCounters  map[string]models.ByteCounters // last queue byte counters by username
+	usageResets    map[string]bool                 // users that reconnected since the last sample
+
+	addressIndex map[string]models.AddressListEntry // AddressLists by exact IP
+
+	isolationsSynced bool
+	isolationsLoaded bool // Isolations came from the database (not just made while it was down)
+
+	lastReconcile *models.ReconcileResult
 }
 
-func NewWorker(r models.Router, wg *sync.WaitGroup) *Worker {
+func NewWorker(r models.Router, store database.Store, wg *sync.WaitGroup) *Worker {
 	return &Worker{
-		Router:  r,
-		CmdChan: make(chan Command, 10), // Buffered channel
-		wg:      wg,
+		Router:      r,
+		store:       store,
+		CmdChan:     make(chan Command, CommandBuffer), // Buffered channel
+		wg:          wg,
+		stop:        make(chan struct{}),
+		Isolations:  make(map[string]*models.Isolation),
+		poolAlerts:  make(map[string]bool),
+		usageResets: make(map[string]bool),
 	}
 }
 
@@ -52,19 +78,25 @@ func (w *Worker) Start() {
 	go w.metricsLoop() // Start background metrics/keepalive
 
 	for {
+		if w.stopped() {
+			return
+		}
+
 		// 1. Try to Connect
 		logger.Info("Dialing router...", zap.String("host", w.Router.Host), zap.Int("port", w.Router.Port), zap.String("user", w.Router.Username))
 		client, err := mikrotik.NewClient(w.Router)
 		
 		if err != nil {
-			logger.Error("Connection failed, retrying in 5s...", zap.String("host", w.Router.Host), zap.Int("port", w.Router.Port), zap.Error(err))
+			logger.Error("Connection failed, retrying...", zap.String("host", w.Router.Host), zap.Int("port", w.Router.Port), zap.Duration("in", ConnectRetry), zap.Error(err))
 			w.IsOnline = false
 			
 			// If we fail the first connect, we consider this worker "warmed up" (but failed)
 			// so we don't block the entire server forever.
 			signalReady()
 			
-			time.Sleep(5 * time.Second)
+			if !w.sleep(ConnectRetry) {
+				return
+			}
 			continue // Retry loop
 		}
 
@@ -76,10 +108,16 @@ func (w *Worker) Start() {
 
 		// 3. WARMUP: Fetch initial data IMMEDIATELY
 		logger.Info("Warming up cache...", zap.String("host", w.Router.Host))
+		w.loadIsolations()
 		w.refreshMetrics() // Force immediate fetch
 		
-		// Trigger initial Sync of Secrets (Async)
-		w.CmdChan <- Command{Type: CmdSync}
+		// Trigger initial Sync of Secrets (Async). Never block here: the buffer
+		// may be full and nothing drains it until handleCommands runs.
+		select {
+		case w.CmdChan <- Command{Type: CmdSync}:
+		default:
+			logger.Warn("Command queue full, skipping initial sync", zap.String("host", w.Router.Host))
+		}
 		
 		signalReady()      // Signal we are ready to serve
 
@@ -87,6 +125,12 @@ func (w *Worker) Start() {
 		w.handleCommands()
 
 		// 5. Cleanup after disconnect
+		if w.stopped() {
+			logger.Info("Router removed from inventory, stopping worker", zap.String("host", w.Router.Host))
+			w.IsOnline = false
+			w.Client.Close()
+			return
+		}
 		logger.Warn("Router Disconnected. Cleaning up...", zap.String("host", w.Router.Host))
 		if w.IsOnline {
 			SendWebhook("router.down", w.Router.ID, w.Router.Host, "Connection lost")
@@ -97,100 +141,228 @@ func (w *Worker) Start() {
 		}
 		
 		// 6. Backoff before reconnecting
-		time.Sleep(3 * time.Second)
+		if !w.sleep(ReconnectDelay) {
+			return
+		}
+	}
+}
+
+// Stop ends the worker's loops and closes its connection, e.g. when the router
+// is removed from the inventory. Queued commands are not answered.
+func (w *Worker) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
+
+func (w *Worker) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for d and reports false if the worker was stopped meanwhile
+func (w *Worker) sleep(d time.Duration) bool {
+	select {
+	case <-w.stop:
+		return false
+	case <-time.After(d):
+		return true
 	}
 }
 
 func (w *Worker) handleCommands() {
-	for cmd := range w.CmdChan {
+	for {
+		var cmd Command
+		select {
+		case <-w.stop:
+			return
+		case cmd = <-w.CmdChan:
+		}
 		// Process command here
 		// If TCP fails, we break the loop and let Start() reconnect
 		logger.Info("Received command", zap.String("type", string(cmd.Type)))
-		
-		var err error
-		switch cmd.Type {
-		case CmdSync:
-			secrets, errSync := w.Client.GetAllSecrets()
-			if errSync != nil {
-				err = errSync
-				logger.Error("Failed to fetch secrets for sync", zap.String("router", w.Router.Name), zap.Error(err))
-			} else {
-				go func(secrets []models.PPPoESecret, routerID int) {
-					count := 0
-					for _, s := range secrets {
-						if dbErr := database.UpsertUser(s.Name, routerID, s.Profile, s.RemoteAddress, !s.Disabled); dbErr == nil {
-							count++
-						}
-					}
-					logger.Info("Synced Secrets to DB", zap.String("router", w.Router.Name), zap.Int("synced", count), zap.Int("total", len(secrets)))
-				}(secrets, w.Router.ID)
-				
-				cmd.Result <- "Synced"
-			}
-		
-		case CmdCreateSecret:
-			payload := cmd.Payload.(map[string]string)
-			err = w.Client.AddSecret(
-				payload["user"], 
-				payload["password"], 
-				payload["profile"], 
-				payload["local_ip"], 
-				payload["remote_ip"], 
-				payload["comment"],
-			)
-		
-		case CmdUpdateSecret:
-			payload := cmd.Payload.(map[string]string)
-			err = w.Client.SetSecretProfile(
-				payload["user"],
-				payload["profile"],
-			)
-
-		case CmdIsolate:
-			payload := cmd.Payload.(map[string]string)
-			if payload["action"] == "add" {
-				err = w.Client.AddAddressList(payload["ip"], payload["list"], payload["comment"])
-			} else {
-				err = w.Client.RemoveAddressList(payload["ip"], payload["list"])
-			}
 
-		case CmdGetTraffic:
-			target := cmd.Payload.(string)
-			stats, errT := w.Client.GetQueueTraffic(target)
-			if errT != nil {
-				err = errT
-			} else {
-				cmd.Result <- stats
-				return // Early return since we sent result
+		result, err := w.execute(cmd)
+		cmd.reply(result, err)
+	}
+}
+
+// execute runs a single command against the router. It must only be called
+// from the command loop so that RouterOS access stays serialized.
+func (w *Worker) execute(cmd Command) (interface{}, error) {
+	var err error
+	switch cmd.Type {
+	case CmdSync:
+		secrets, errSync := w.Client.GetAllSecrets()
+		if errSync != nil {
+			logger.Error("Failed to fetch secrets for sync", zap.String("router", w.Router.Name), zap.Error(errSync))
+			return nil, errSync
+		}
+		cmd.progress(50) // Fetched; now writing to the database
+		summary, errDB := w.store.SyncUsers(w.Router.ID, secrets, SyncDeletePolicy)
+		if errDB != nil {
+			return nil, errDB
+		}
+		logger.Info("Synced Secrets to DB", zap.String("router", w.Router.Name), zap.Int("total", summary.Total),
+			zap.Int("created", summary.Created), zap.Int("updated", summary.Updated), zap.Int("removed", summary.Removed),
+			zap.Int64("duration_ms", summary.DurationMs))
+		return summary, nil
+
+	case CmdCreateSecret:
+		payload := cmd.Payload.(map[string]string)
+		changed, errC := w.Client.EnsureSecret(
+			payload["user"], 
+			payload["password"], 
+			payload["profile"], 
+			payload["local_ip"], 
+			payload["remote_ip"], 
+			payload["comment"],
+		)
+		if errC != nil {
+			return nil, errC
+		}
+		return Change{Changed: changed}, nil
+
+	case CmdUpdateSecret:
+		payload := cmd.Payload.(map[string]string)
+		if from := payload["if_profile"]; from != "" {
+			// Only change the profile if nobody else has since
+			secret, errG := w.Client.GetSecret(payload["user"])
+			if errG != nil {
+				return nil, errG
+			}
+			if secret == nil || secret.Profile != from {
+				return Change{Changed: false}, nil
 			}
+		}
+		changed, errU := w.Client.EnsureSecretProfile(
+			payload["user"],
+			payload["profile"],
+		)
+		if errU != nil {
+			return nil, errU
+		}
+		return Change{Changed: changed}, nil
 
-		case CmdBackup:
-			name := cmd.Payload.(string)
-			err = w.Client.RunBackup(name)
+	case CmdIsolate:
+		payload := cmd.Payload.(map[string]string)
+		if payload["user"] != "" {
+			return w.isolateUser(payload)
+		}
+		var changed bool
+		if payload["action"] == "add" {
+			changed, err = w.Client.EnsureAddressList(payload["ip"], payload["list"], payload["comment"])
+		} else {
+			changed, err = w.Client.EnsureAddressListAbsent(payload["ip"], payload["list"])
+		}
+		if err != nil {
+			return nil, err
+		}
+		return Change{Changed: changed}, nil
 
-		case CmdRefreshMetrics:
-			w.refreshMetrics()
+	case CmdKick:
+		user := cmd.Payload.(string)
+		kicked, errK := w.Client.KickUser(user)
+		if errK != nil {
+			return nil, errK
 		}
+		return kicked, nil
+
+	case CmdSuspend:
+		return w.suspendUser(cmd.Payload.(map[string]string))
 
-		if cmd.Error != nil {
-			cmd.Error <- err
+	case CmdResume:
+		return w.resumeUser(cmd.Payload.(string))
+
+	case CmdReconcile:
+		return w.reconcile(cmd.Payload.(ReconcileOptions), cmd.progress)
+
+	case CmdGetSecrets:
+		return w.Client.GetAllSecrets()
+
+	case CmdGetTraffic:
+		target := cmd.Payload.(string)
+		stats, errT := w.Client.GetQueueTraffic(target)
+		if errT != nil {
+			return nil, errT
 		}
-		if cmd.Result != nil && err == nil {
-			cmd.Result <- "Success"
+		return stats, nil
+
+	case CmdBackup:
+		name := cmd.Payload.(string)
+		err = w.Client.RunBackup(name)
+
+	case CmdRefreshMetrics:
+		w.refreshMetrics()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return "Success", nil
+}
+
+// Execute queues a command and waits for its outcome. It is the entry point
+// for callers outside the worker (API handlers, background engines). Nothing
+// is queued while the router is offline, so the queue cannot fill up before
+// the worker reconnects.
+func (w *Worker) Execute(cmdType CommandType, payload interface{}, timeout time.Duration) (interface{}, error) {
+	return w.ExecuteWithProgress(cmdType, payload, timeout, nil)
+}
+
+// ExecuteWithProgress is Execute for async jobs: progress is called from the
+// worker as a long command (sync, reconcile) advances
+func (w *Worker) ExecuteWithProgress(cmdType CommandType, payload interface{}, timeout time.Duration, progress func(int)) (interface{}, error) {
+	if !w.IsOnline {
+		return nil, ErrRouterOffline
+	}
+
+	cmd := Command{
+		Type:     cmdType,
+		Payload:  payload,
+		Result:   make(chan interface{}, 1),
+		Error:    make(chan error, 1),
+		Progress: progress,
+	}
+
+	select {
+	case w.CmdChan <- cmd:
+	case <-time.After(timeout):
+		return nil, ErrWorkerBusy
+	}
+
+	select {
+	case err := <-cmd.Error:
+		if err != nil {
+			return nil, err
 		}
+	case <-time.After(timeout):
+		return nil, ErrTimeout
 	}
+	return <-cmd.Result, nil
 }
 
 func (w *Worker) metricsLoop() {
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(MetricsInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
 		if !w.IsOnline {
 			continue
 		}
 		// Thread Safety: Send command instead of direct call
-		w.CmdChan <- Command{Type: CmdRefreshMetrics}
+		select {
+		case w.CmdChan <- Command{Type: CmdRefreshMetrics}:
+		case <-w.stop:
+			return
+		}
 	}
 }
 
@@ -209,8 +381,14 @@ func (w *Worker) refreshMetrics() {
 		// logging error optional
 	}
 
+	entries, errList := w.Client.GetAddressListEntries(IsolationLists)
+	if errList != nil {
+		logger.Error("Failed to fetch address lists", zap.String("host", w.Router.Host), zap.Error(errList))
+	}
+
 	// Update Cache
 	w.Lock.Lock()
+	prev := w.ActiveUsers
 	if err == nil {
 		w.ActiveUsers = users
 		logger.Info("Worker Cache Updated", zap.String("router", w.Router.Name), zap.Int("active_users", len(w.ActiveUsers)))
@@ -218,7 +396,27 @@ func (w *Worker) refreshMetrics() {
 	if errRes == nil {
 		w.SystemResource = res
 	}
+	if errList == nil {
+		w.AddressLists = entries
+		w.addressIndex = indexAddressList(entries)
+	}
 	w.Lock.Unlock()
+
+	w.refreshPools()
+
+	if err == nil {
+		events := diffSessions(prev, users)
+		for _, ev := range events {
+			if ev.Type == SessionConnected {
+				w.usageResets[ev.User.Name] = true
+			}
+		}
+		w.collectUsage()
+		w.followIsolations(events)
+		if w.pool != nil {
+			w.pool.observeSessions(w, users, events)
+		}
+	}
 	
 	// logger.Info("Metrics refreshed", zap.String("host", w.Router.Host), zap.Int("users", len(users)))
 }
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteOffline(t *testing.T) {
+	w := NewWorker(models.Router{ID: 1}, nil, nil)
+
+	// More calls than the queue holds: none may be queued for the reconnect
+	for i := 0; i <= CommandBuffer; i++ {
+		_, err := w.Execute(CmdKick, "alice", time.Second)
+		assert.ErrorIs(t, err, ErrRouterOffline)
+	}
+	assert.Empty(t, w.CmdChan)
+}
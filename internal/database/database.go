@@ -1,67 +1,45 @@
 package database
 
 import (
-	"database/sql"
-	"os"
-	"time"
-
-	_ "github.com/go-sql-driver/mysql"
+	"skynet-net-engine-api/internal/config"
 	"skynet-net-engine-api/pkg/logger"
 	"go.uber.org/zap"
 )
 
-var DB *sql.DB
+// Connection settings, set by Configure. AutoMigrate applies pending migrations
+// on startup; with it off they only run through "netengine migrate up".
+var (
+	Driver      = DriverMySQL
+	DSN         string
+	AutoMigrate = true
+)
 
-func Init() {
-	dsn := os.Getenv("DB_DSN")
-	if dsn == "" {
-		// Default fallback for development
-		// User provided: fairusinampratama, NO pass
-		dsn = "fairusinampratama@tcp(127.0.0.1:3306)/netengine?parseTime=true"
-	}
+// Configure applies the database section of the config; call it before Init or Connect
+func Configure(c config.Database) {
+	Driver, DSN, AutoMigrate = c.Driver, c.DSN, c.AutoMigrate
+	SnapshotPath, SnapshotKey = c.SnapshotPath, c.SnapshotKey
+}
 
-	var err error
-	DB, err = sql.Open("mysql", dsn)
-	if err != nil {
-		logger.Fatal("Failed to open database connection")
+func Init() {
+	if err := Connect(); err != nil {
+		logger.Warn("Failed to ping database - Running in Offline Mode", zap.Error(err))
+		goOffline(err)
+		return
 	}
-
-	DB.SetMaxOpenConns(10)
-	DB.SetMaxIdleConns(5)
-	DB.SetConnMaxLifetime(time.Hour)
-
-	if err := DB.Ping(); err != nil {
-		logger.Warn("Failed to ping database - Running in Offline/Mock Mode", zap.Error(err))
-	} else {
-		logger.Info("Database connected successfully")
+	logger.Info("Database connected successfully")
+	if AutoMigrate {
 		Migrate()
 	}
 }
 
-func Migrate() {
-	// 1. Ensure Table Exists (Previous logic assumed it exists, but good to be safe)
-	// We skip CREATE TABLE for now as it's likely handled elsewhere or pre-existing
-	
-	// 2. Add remote_address column if missing
-	// We use IGNORE or check approach. Simplest for MySQL is a conditional procedure or just try-catch approach.
-	// Since we can't easily do try-catch on DDL in Go without parsing error, we'll try to add it.
-	// Duplicate column error is fine to ignore if we check strictly, but here we'll just run generic ADD COLUMN IF NOT EXISTS logic
-	// MySQL 8.0 support IF NOT EXISTS in ADD COLUMN, but MariaDB might not in all versions.
-	// We will simply try to query it first.
-	
-	query := "SHOW COLUMNS FROM pppoe_users LIKE 'remote_address'"
-	rows, err := DB.Query(query)
+// Connect opens Default from Driver ("mysql" or "sqlite") and DSN, then pings it.
+// Default is usable (for a later retry) even when the ping fails.
+func Connect() error {
+	store, err := Open(Driver, DSN)
 	if err != nil {
-		logger.Error("Failed to check schema", zap.Error(err))
-		return
-	}
-	defer rows.Close()
-	
-	if !rows.Next() {
-		logger.Info("Migrating DB: Adding remote_address to pppoe_users")
-		_, err := DB.Exec("ALTER TABLE pppoe_users ADD COLUMN remote_address VARCHAR(45) DEFAULT NULL")
-		if err != nil {
-			logger.Error("Failed to migrate database", zap.Error(err))
-		}
+		logger.Fatal("Failed to open database connection", zap.Error(err))
 	}
+	Use(store)
+
+	return store.Ping()
 }
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+// Default is the store opened by Init/Connect. Change it with Use; it is
+// swapped at runtime when the engine goes offline and back.
+var Default Store
+
+var defaultMu sync.RWMutex
+
+// Use replaces Default and returns the previous store
+func Use(s Store) Store {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	prev := Default
+	Default = s
+	return prev
+}
+
+func current() Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return Default
+}
+
+// Close closes Default
+func Close() error {
+	return current().Close()
+}
+
+// Live is a Store that runs every call against Default as it is at the time
+// of the call, so its holders follow the switch to the snapshot and back.
+// The engine hands it to the worker pool and the API; tests pass their own store.
+var Live Store = liveStore{}
+
+type liveStore struct{}
+
+func (liveStore) Ping() error {
+	return current().Ping()
+}
+
+func (liveStore) Close() error {
+	return current().Close()
+}
+
+func (liveStore) MigrateUp(steps int) (int, error) {
+	return current().MigrateUp(steps)
+}
+
+func (liveStore) MigrateDown(steps int) (int, error) {
+	return current().MigrateDown(steps)
+}
+
+func (liveStore) MigrationStatus() ([]MigrationState, error) {
+	return current().MigrationStatus()
+}
+
+func (liveStore) GetAllRouters() ([]models.Router, error) {
+	return current().GetAllRouters()
+}
+
+func (liveStore) SaveRouter(r models.Router) error {
+	return current().SaveRouter(r)
+}
+
+func (liveStore) UpsertUser(username string, routerID int, profile string, remoteAddress string, isEnabled bool) error {
+	return current().UpsertUser(username, routerID, profile, remoteAddress, isEnabled)
+}
+
+func (liveStore) SyncUsers(routerID int, secrets []models.PPPoESecret, policy string) (models.SyncSummary, error) {
+	return current().SyncUsers(routerID, secrets, policy)
+}
+
+func (liveStore) GetUsersByRouter(routerID int) (map[string]DBUser, error) {
+	return current().GetUsersByRouter(routerID)
+}
+
+func (liveStore) GetUser(routerID int, username string) (*DBUser, error) {
+	return current().GetUser(routerID, username)
+}
+
+func (liveStore) FindUserRouterIDs(username string) ([]int, error) {
+	return current().FindUserRouterIDs(username)
+}
+
+func (liveStore) FindUsers(username string) ([]DBUser, error) {
+	return current().FindUsers(username)
+}
+
+func (liveStore) FindUsersByAddress(ip string) ([]DBUser, error) {
+	return current().FindUsersByAddress(ip)
+}
+
+func (liveStore) FindUsersByCallerID(mac string) ([]DBUser, error) {
+	return current().FindUsersByCallerID(mac)
+}
+
+func (liveStore) GetStaticAddresses() (map[string][]DBUser, error) {
+	return current().GetStaticAddresses()
+}
+
+func (liveStore) SaveIsolation(iso models.Isolation) error {
+	return current().SaveIsolation(iso)
+}
+
+func (liveStore) DeleteIsolation(username string, routerID int, list string) error {
+	return current().DeleteIsolation(username, routerID, list)
+}
+
+func (liveStore) GetIsolationsByRouter(routerID int) ([]models.Isolation, error) {
+	return current().GetIsolationsByRouter(routerID)
+}
+
+func (liveStore) SaveSuspension(s models.Suspension) error {
+	return current().SaveSuspension(s)
+}
+
+func (liveStore) DeleteSuspension(username string, routerID int) error {
+	return current().DeleteSuspension(username, routerID)
+}
+
+func (liveStore) SetSuspensionPreviousProfile(username string, routerID int, profile string) error {
+	return current().SetSuspensionPreviousProfile(username, routerID, profile)
+}
+
+func (liveStore) GetSuspension(username string, routerID int) (*models.Suspension, error) {
+	return current().GetSuspension(username, routerID)
+}
+
+func (liveStore) GetSuspensionsByRouter(routerID int) (map[string]models.Suspension, error) {
+	return current().GetSuspensionsByRouter(routerID)
+}
+
+func (liveStore) OpenSession(s models.Session) error {
+	return current().OpenSession(s)
+}
+
+func (liveStore) CloseSession(routerID int, username, address string, endedAt time.Time) error {
+	return current().CloseSession(routerID, username, address, endedAt)
+}
+
+func (liveStore) CloseSessions(sessions []models.Session, endedAt time.Time) {
+	current().CloseSessions(sessions, endedAt)
+}
+
+func (liveStore) GetOpenSessions(routerID int) ([]models.Session, error) {
+	return current().GetOpenSessions(routerID)
+}
+
+func (liveStore) GetUserSessions(username string, routerID int, from, to time.Time, limit int) ([]models.Session, error) {
+	return current().GetUserSessions(username, routerID, from, to, limit)
+}
+
+func (liveStore) AddUsage(routerID int, hour time.Time, deltas []models.ByteCounters) error {
+	return current().AddUsage(routerID, hour, deltas)
+}
+
+func (liveStore) GetHourlyUsage(username string, routerID int, from, to time.Time) ([]models.UsagePoint, error) {
+	return current().GetHourlyUsage(username, routerID, from, to)
+}
+
+func (liveStore) GetUsageTotals(since time.Time) (map[int]map[string]int64, error) {
+	return current().GetUsageTotals(since)
+}
+
+func (liveStore) GetFUPRules() ([]models.FUPRule, error) {
+	return current().GetFUPRules()
+}
+
+func (liveStore) SaveFUPRule(r models.FUPRule) error {
+	return current().SaveFUPRule(r)
+}
+
+func (liveStore) DeleteFUPRule(id int) error {
+	return current().DeleteFUPRule(id)
+}
+
+func (liveStore) GetFUPStates() ([]models.FUPState, error) {
+	return current().GetFUPStates()
+}
+
+func (liveStore) SaveFUPState(s models.FUPState) error {
+	return current().SaveFUPState(s)
+}
+
+func (liveStore) DeleteFUPState(username string, routerID int) error {
+	return current().DeleteFUPState(username, routerID)
+}
+
+func (liveStore) AddFUPEvent(e models.FUPEvent) error {
+	return current().AddFUPEvent(e)
+}
+
+func (liveStore) GetFUPEvents(username string, limit int) ([]models.FUPEvent, error) {
+	return current().GetFUPEvents(username, limit)
+}
+
+func (liveStore) CreateScheduledJob(j models.ScheduledJob) (int64, error) {
+	return current().CreateScheduledJob(j)
+}
+
+func (liveStore) UpdateScheduledJob(j models.ScheduledJob) (bool, error) {
+	return current().UpdateScheduledJob(j)
+}
+
+func (liveStore) CancelScheduledJob(id int64) (bool, error) {
+	return current().CancelScheduledJob(id)
+}
+
+func (liveStore) GetScheduledJob(id int64) (*models.ScheduledJob, error) {
+	return current().GetScheduledJob(id)
+}
+
+func (liveStore) GetScheduledJobs(status, username string) ([]models.ScheduledJob, error) {
+	return current().GetScheduledJobs(status, username)
+}
+
+func (liveStore) GetDueJobs(now time.Time) ([]models.ScheduledJob, error) {
+	return current().GetDueJobs(now)
+}
+
+func (liveStore) SaveJob(j models.Job) error {
+	return current().SaveJob(j)
+}
+
+func (liveStore) GetJob(id string) (*models.Job, error) {
+	return current().GetJob(id)
+}
+
+func (liveStore) GetJobs(status string, limit int) ([]models.Job, error) {
+	return current().GetJobs(status, limit)
+}
+
+func (liveStore) FailInterruptedJobs(now time.Time) (int64, error) {
+	return current().FailInterruptedJobs(now)
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) name() string               { return DriverMySQL }
+func (mysqlDialect) onConflict(string) string   { return "ON DUPLICATE KEY UPDATE" }
+func (mysqlDialect) inserted(col string) string { return "VALUES(" + col + ")" }
+
+// lock takes a MySQL named lock, so two servers (or the CLI and a server) never migrate at once
+func (mysqlDialect) lock(conn *sql.Conn) (func(error) error, error) {
+	ctx := context.Background()
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLock, migrationLockTimeout).Scan(&got); err != nil {
+		return nil, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return nil, errors.New("another migration is running")
+	}
+	return func(err error) error {
+		conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLock)
+		return err
+	}, nil
+}
+
+// transactionalDDL is false: MySQL commits DDL implicitly
+func (mysqlDialect) transactionalDDL() bool { return false }
+
+// syncLock takes a per-router named lock, so the server and sync-users never sync the same router at once
+func (mysqlDialect) syncLock(conn *sql.Conn, routerID int) (func(), error) {
+	ctx := context.Background()
+	name := fmt.Sprintf("netengine_sync_%d", routerID)
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, syncLockTimeout).Scan(&got); err != nil {
+		return nil, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return nil, fmt.Errorf("router %d is already being synced", routerID)
+	}
+	return func() { conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name) }, nil
+}
+
+// skippable matches duplicate column (1060) and duplicate key (1061) errors
+func (mysqlDialect) skippable(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && (myErr.Number == 1060 || myErr.Number == 1061)
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) name() string { return DriverSQLite }
+func (sqliteDialect) onConflict(keys string) string {
+	return "ON CONFLICT (" + keys + ") DO UPDATE SET"
+}
+func (sqliteDialect) inserted(col string) string { return "excluded." + col }
+
+// lock holds a write transaction for the whole run; SQLite DDL is transactional,
+// so the applied migrations and their schema_migrations rows commit together,
+// or are all rolled back when the run fails.
+func (sqliteDialect) lock(conn *sql.Conn) (func(error) error, error) {
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, err
+	}
+	return func(err error) error {
+		if err != nil {
+			conn.ExecContext(ctx, "ROLLBACK")
+			return err
+		}
+		_, err = conn.ExecContext(ctx, "COMMIT")
+		return err
+	}, nil
+}
+
+func (sqliteDialect) transactionalDDL() bool { return true }
+
+// syncLock has nothing to do: transactions begin IMMEDIATE (see sqliteDSN),
+// so a sync holds the database write lock from its first read.
+func (sqliteDialect) syncLock(*sql.Conn, int) (func(), error) { return func() {}, nil }
+
+func (sqliteDialect) skippable(err error) bool {
+	return strings.Contains(err.Error(), "duplicate column name")
+}
+
+// sqliteDSN adds the pragmas the engine relies on to a file name or DSN:
+// wait for locks instead of failing, take the write lock when a transaction
+// begins (a read-then-write transaction could otherwise fail to upgrade while
+// another process writes), and store times in a sortable format.
+func sqliteDSN(dsn string) string {
+	if dsn == "" {
+		dsn = "netengine.db"
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=busy_timeout(5000)&_txlock=immediate&_time_format=sqlite"
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// GetFUPRules fetches all quota rules
+func (st *SQLStore) GetFUPRules() ([]models.FUPRule, error) {
+	rows, err := st.db.Query("SELECT id, profile, quota_bytes, action, throttle_profile, address_list, enabled FROM fup_rules ORDER BY profile")
+	if err != nil {
+		logger.Error("Failed to fetch FUP rules", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]models.FUPRule, 0)
+	for rows.Next() {
+		var r models.FUPRule
+		var throttleProfile, addressList sql.NullString
+		if err := rows.Scan(&r.ID, &r.Profile, &r.QuotaBytes, &r.Action, &throttleProfile, &addressList, &r.Enabled); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		r.ThrottleProfile = throttleProfile.String
+		r.AddressList = addressList.String
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// SaveFUPRule inserts or replaces the rule of a profile
+func (st *SQLStore) SaveFUPRule(r models.FUPRule) error {
+	query := `
+		INSERT INTO fup_rules (profile, quota_bytes, action, throttle_profile, address_list, enabled)
+		VALUES (?, ?, ?, ?, ?, ?)
+	` + st.upsert("profile", "quota_bytes", "action", "throttle_profile", "address_list", "enabled")
+	_, err := st.db.Exec(query, r.Profile, r.QuotaBytes, r.Action, r.ThrottleProfile, r.AddressList, r.Enabled)
+	if err != nil {
+		logger.Error("Failed to save FUP rule", zap.String("profile", r.Profile), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteFUPRule removes a rule. Subscribers it throttled are restored at the next cycle.
+func (st *SQLStore) DeleteFUPRule(id int) error {
+	_, err := st.db.Exec("DELETE FROM fup_rules WHERE id = ?", id)
+	if err != nil {
+		logger.Error("Failed to delete FUP rule", zap.Int("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// GetFUPStates fetches every subscriber currently over quota
+func (st *SQLStore) GetFUPStates() ([]models.FUPState, error) {
+	rows, err := st.db.Query("SELECT username, router_id, rule_id, action, original_profile, throttle_profile, address_list, cycle_start, applied_at FROM fup_states ORDER BY applied_at")
+	if err != nil {
+		logger.Error("Failed to fetch FUP states", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make([]models.FUPState, 0)
+	for rows.Next() {
+		var s models.FUPState
+		var throttleProfile, addressList sql.NullString
+		if err := rows.Scan(&s.Username, &s.RouterID, &s.RuleID, &s.Action, &s.OriginalProfile, &throttleProfile, &addressList, &s.CycleStart, &s.AppliedAt); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		s.ThrottleProfile, s.AddressList = throttleProfile.String, addressList.String
+		states = append(states, s)
+	}
+
+	return states, nil
+}
+
+// SaveFUPState records that a subscriber has been throttled or isolated
+func (st *SQLStore) SaveFUPState(s models.FUPState) error {
+	_, err := st.db.Exec(
+		"INSERT INTO fup_states (username, router_id, rule_id, action, original_profile, throttle_profile, address_list, cycle_start, applied_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		s.Username, s.RouterID, s.RuleID, s.Action, s.OriginalProfile, s.ThrottleProfile, s.AddressList, s.CycleStart, s.AppliedAt,
+	)
+	if err != nil {
+		logger.Error("Failed to save FUP state", zap.String("user", s.Username), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteFUPState forgets a subscriber once it has been restored
+func (st *SQLStore) DeleteFUPState(username string, routerID int) error {
+	_, err := st.db.Exec("DELETE FROM fup_states WHERE username = ? AND router_id = ?", username, routerID)
+	if err != nil {
+		logger.Error("Failed to delete FUP state", zap.String("user", username), zap.Error(err))
+	}
+	return err
+}
+
+// AddFUPEvent appends to the FUP transition log
+func (st *SQLStore) AddFUPEvent(e models.FUPEvent) error {
+	_, err := st.db.Exec(
+		"INSERT INTO fup_events (username, router_id, rule_id, event, action, usage_bytes, quota_bytes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		e.Username, e.RouterID, e.RuleID, e.Event, e.Action, e.UsageBytes, e.QuotaBytes, e.CreatedAt,
+	)
+	if err != nil {
+		logger.Error("Failed to record FUP event", zap.String("user", e.Username), zap.Error(err))
+	}
+	return err
+}
+
+// GetFUPEvents fetches the most recent transitions, optionally for one username
+func (st *SQLStore) GetFUPEvents(username string, limit int) ([]models.FUPEvent, error) {
+	query := "SELECT id, username, router_id, rule_id, event, action, usage_bytes, quota_bytes, created_at FROM fup_events"
+	args := []interface{}{}
+	if username != "" {
+		query += " WHERE username = ?"
+		args = append(args, username)
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := st.db.Query(query, args...)
+	if err != nil {
+		logger.Error("Failed to fetch FUP events", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.FUPEvent, 0)
+	for rows.Next() {
+		var e models.FUPEvent
+		if err := rows.Scan(&e.ID, &e.Username, &e.RouterID, &e.RuleID, &e.Event, &e.Action, &e.UsageBytes, &e.QuotaBytes, &e.CreatedAt); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// GetUsageTotals sums rx+tx bytes per router and username since a point in time
+func (st *SQLStore) GetUsageTotals(since time.Time) (map[int]map[string]int64, error) {
+	rows, err := st.db.Query("SELECT router_id, username, SUM(rx_bytes + tx_bytes) FROM pppoe_usage WHERE period_start >= ? GROUP BY router_id, username", since)
+	if err != nil {
+		logger.Error("Failed to sum usage", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[int]map[string]int64)
+	for rows.Next() {
+		var routerID int
+		var username string
+		var total int64
+		if err := rows.Scan(&routerID, &username, &total); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		if totals[routerID] == nil {
+			totals[routerID] = make(map[string]int64)
+		}
+		totals[routerID][username] = total
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SaveIsolation inserts or updates a username-based isolation
+func (st *SQLStore) SaveIsolation(iso models.Isolation) error {
+	query := `
+		INSERT INTO isolations (username, router_id, list_name, comment, address)
+		VALUES (?, ?, ?, ?, ?)
+	` + st.upsert("username, router_id, list_name", "comment", "address") + ", updated_at = CURRENT_TIMESTAMP"
+	_, err := st.db.Exec(query, iso.Username, iso.RouterID, iso.List, iso.Comment, iso.Address)
+	if err != nil {
+		logger.Error("Failed to save isolation", zap.String("user", iso.Username), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteIsolation removes an isolation once it has been lifted
+func (st *SQLStore) DeleteIsolation(username string, routerID int, list string) error {
+	_, err := st.db.Exec("DELETE FROM isolations WHERE username = ? AND router_id = ? AND list_name = ?", username, routerID, list)
+	if err != nil {
+		logger.Error("Failed to delete isolation", zap.String("user", username), zap.Error(err))
+	}
+	return err
+}
+
+// GetIsolationsByRouter fetches all username-based isolations for a router
+func (st *SQLStore) GetIsolationsByRouter(routerID int) ([]models.Isolation, error) {
+	rows, err := st.db.Query("SELECT username, router_id, list_name, comment, address FROM isolations WHERE router_id = ?", routerID)
+	if err != nil {
+		logger.Error("Failed to fetch isolations", zap.Int("router_id", routerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	isolations := make([]models.Isolation, 0)
+	for rows.Next() {
+		var iso models.Isolation
+		var comment, address sql.NullString
+		if err := rows.Scan(&iso.Username, &iso.RouterID, &iso.List, &comment, &address); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		iso.Comment = comment.String
+		iso.Address = address.String
+		isolations = append(isolations, iso)
+	}
+
+	return isolations, nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SaveJob inserts or updates an async job
+func (st *SQLStore) SaveJob(j models.Job) error {
+	var result sql.NullString
+	if j.Result != nil {
+		data, err := json.Marshal(j.Result)
+		if err != nil {
+			return err
+		}
+		result = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := `
+		INSERT INTO async_jobs (id, type, router_id, status, progress, result, error, created_at, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	` + st.upsert("id", "status", "progress", "result", "error", "started_at", "finished_at")
+	_, err := st.db.Exec(query, j.ID, j.Type, j.RouterID, j.Status, j.Progress, result, j.Error, j.CreatedAt, j.StartedAt, j.FinishedAt)
+	if err != nil {
+		logger.Error("Failed to save job", zap.String("id", j.ID), zap.Error(err))
+	}
+	return err
+}
+
+const selectJobs = "SELECT id, type, router_id, status, progress, result, error, created_at, started_at, finished_at FROM async_jobs"
+
+// GetJob fetches a job, or nil if it does not exist
+func (st *SQLStore) GetJob(id string) (*models.Job, error) {
+	j, err := scanJob(st.db.QueryRow(selectJobs+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to fetch job", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return j, nil
+}
+
+// GetJobs lists the most recent jobs, optionally filtered by status
+func (st *SQLStore) GetJobs(status string, limit int) ([]models.Job, error) {
+	where, args := "", []interface{}{}
+	if status != "" {
+		where = " WHERE status = ?"
+		args = append(args, status)
+	}
+	args = append(args, limit)
+
+	rows, err := st.db.Query(selectJobs+where+" ORDER BY created_at DESC LIMIT ?", args...)
+	if err != nil {
+		logger.Error("Failed to fetch jobs", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]models.Job, 0)
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, nil
+}
+
+// FailInterruptedJobs marks jobs that were queued or running when the
+// engine stopped as failed, so clients polling them get an answer.
+func (st *SQLStore) FailInterruptedJobs(now time.Time) (int64, error) {
+	res, err := st.db.Exec("UPDATE async_jobs SET status = ?, error = ?, finished_at = ? WHERE status IN (?, ?)",
+		models.JobFailed, "interrupted by restart", now, models.JobQueued, models.JobRunning)
+	if err != nil {
+		logger.Error("Failed to close interrupted jobs", zap.Error(err))
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanJob(row scanner) (*models.Job, error) {
+	var j models.Job
+	var result, errMsg sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	if err := row.Scan(&j.ID, &j.Type, &j.RouterID, &j.Status, &j.Progress, &result, &errMsg, &j.CreatedAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	if result.Valid {
+		var v interface{}
+		if json.Unmarshal([]byte(result.String), &v) == nil {
+			j.Result = v
+		}
+	}
+	j.Error = errMsg.String
+	j.StartedAt = nullTime(startedAt)
+	j.FinishedAt = nullTime(finishedAt)
+	return &j, nil
+}
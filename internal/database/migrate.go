@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Migrations live in migrations/<driver>/ as NNNN_name.up.sql and NNNN_name.down.sql.
+// Add a new pair with the next number to every driver; never edit one that has shipped.
+//
+//go:embed migrations/mysql/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+const (
+	migrationLock        = "netengine_migrate"
+	migrationLockTimeout = 60 // seconds
+)
+
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at DATETIME NOT NULL
+	)
+`
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState is a migration and when it was applied (nil if pending)
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Migrate applies every pending migration to Default. Errors are logged; the server keeps starting.
+func Migrate() {
+	applied, err := current().MigrateUp(0)
+	if err != nil {
+		logger.Error("Failed to migrate database", zap.Error(err))
+		return
+	}
+	if applied > 0 {
+		logger.Info("Database migrated", zap.Int("applied", applied))
+	}
+}
+
+// MigrateUp applies up to steps pending migrations in order (all of them if steps <= 0)
+// and returns how many were applied.
+func (st *SQLStore) MigrateUp(steps int) (int, error) {
+	migrations, err := st.loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = st.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if steps > 0 && applied == steps {
+				break
+			}
+			logger.Info("Applying migration", zap.Int("version", m.Version), zap.String("name", m.Name))
+			if err := st.execMigration(conn, m.Up); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(context.Background(),
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				m.Version, m.Name, time.Now()); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil && st.dialect.transactionalDDL() {
+		applied = 0 // The whole run was rolled back
+	}
+	return applied, err
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first (one if steps <= 0),
+// and returns how many were reverted.
+func (st *SQLStore) MigrateDown(steps int) (int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	migrations, err := st.loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	err = st.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			logger.Info("Reverting migration", zap.Int("version", m.Version), zap.String("name", m.Name))
+			if err := st.execMigration(conn, m.Down); err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(context.Background(),
+				"DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	if err != nil && st.dialect.transactionalDDL() {
+		reverted = 0
+	}
+	return reverted, err
+}
+
+// MigrationStatus lists every known migration with its applied time
+func (st *SQLStore) MigrationStatus() ([]MigrationState, error) {
+	migrations, err := st.loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := st.db.Exec(createMigrationsTable); err != nil {
+		return nil, err
+	}
+	rows, err := st.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if t, ok := done[m.Version]; ok {
+			state.AppliedAt = &t
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// withMigrationLock runs fn on one connection holding the migration lock
+func (st *SQLStore) withMigrationLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := st.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	unlock, err := st.dialect.lock(conn)
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.ExecContext(ctx, createMigrationsTable); err == nil {
+		err = fn(conn)
+	}
+	return unlock(err)
+}
+
+func appliedVersions(conn *sql.Conn) (map[int]struct{}, error) {
+	rows, err := conn.QueryContext(context.Background(), "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]struct{})
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		done[version] = struct{}{}
+	}
+	return done, rows.Err()
+}
+
+// execMigration runs each statement of a migration. MySQL DDL commits implicitly, so
+// a failed migration is not rolled back there; statements that hit an existing column
+// or index are skipped so databases created before schema_migrations can adopt it.
+func (st *SQLStore) execMigration(conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(context.Background(), stmt); err != nil {
+			if st.dialect.skippable(err) {
+				logger.Warn("Skipping migration statement", zap.Error(err))
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements breaks a script on semicolons that end a line and drops "--" comment lines
+// (the driver runs one statement per Exec)
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			stmts = append(stmts, stmt)
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// loadMigrations reads the embedded files of the store's driver, sorted by version.
+// Every version needs both an up and a down file.
+func (st *SQLStore) loadMigrations() ([]Migration, error) {
+	return parseMigrations(migrationFiles, "migrations/"+st.dialect.name())
+}
+
+func parseMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		name := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		num, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+
+		body, err := fs.ReadFile(fsys, dir+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
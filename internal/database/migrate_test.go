@@ -0,0 +1,64 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	mysql, err := parseMigrations(migrationFiles, "migrations/"+DriverMySQL)
+	require.NoError(t, err)
+	sqlite, err := parseMigrations(migrationFiles, "migrations/"+DriverSQLite)
+	require.NoError(t, err)
+	require.NotEmpty(t, mysql)
+	require.Len(t, sqlite, len(mysql), "every driver needs every migration")
+
+	for i, m := range mysql {
+		assert.Equal(t, i+1, m.Version, "versions must be contiguous")
+		assert.Equal(t, m.Name, sqlite[i].Name)
+		assert.NotEmpty(t, splitStatements(m.Up), m.Name)
+		assert.NotEmpty(t, splitStatements(m.Down), m.Name)
+	}
+}
+
+func TestParseMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_b.up.sql":   {Data: []byte("SELECT 2;")},
+		"m/0002_b.down.sql": {Data: []byte("SELECT -2;")},
+		"m/0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+		"m/0001_a.down.sql": {Data: []byte("SELECT -1;")},
+		"m/README":          {Data: []byte("ignored")},
+	}
+	migrations, err := parseMigrations(fsys, "m")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, "a", migrations[0].Name)
+	assert.Equal(t, "SELECT -2;", migrations[1].Down)
+
+	delete(fsys, "m/0002_b.down.sql")
+	_, err = parseMigrations(fsys, "m")
+	assert.Error(t, err)
+
+	fsys["m/x_bad.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	_, err = parseMigrations(fsys, "m")
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+    x INT DEFAULT 1
+);
+
+ALTER TABLE a ADD COLUMN y INT;
+SELECT 'no trailing semicolon'`
+
+	stmts := splitStatements(script)
+	require.Len(t, stmts, 3)
+	assert.Equal(t, "CREATE TABLE a (\n    x INT DEFAULT 1\n)", stmts[0])
+	assert.Equal(t, "ALTER TABLE a ADD COLUMN y INT", stmts[1])
+	assert.Equal(t, "SELECT 'no trailing semicolon'", stmts[2])
+}
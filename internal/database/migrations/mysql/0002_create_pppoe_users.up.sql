@@ -9,13 +9,9 @@ CREATE TABLE IF NOT EXISTS pppoe_users (
     is_enabled BOOLEAN DEFAULT TRUE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    
+
     UNIQUE KEY unique_user_router (username, router_id),
     INDEX idx_router (router_id),
     INDEX idx_enabled (is_enabled),
     INDEX idx_username (username)
 );
-
--- Optional: Add foreign key to routers table
--- ALTER TABLE pppoe_users ADD CONSTRAINT fk_router
---   FOREIGN KEY (router_id) REFERENCES routers(id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+ALTER TABLE pppoe_users
+    DROP COLUMN remote_address,
+    DROP COLUMN deleted_at,
+    DROP COLUMN local_address,
+    DROP COLUMN caller_id,
+    DROP COLUMN service,
+    DROP COLUMN comment,
+    DROP COLUMN last_logged_out,
+    DROP COLUMN last_caller_id;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS isolations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    list_name VARCHAR(100) NOT NULL,
+    comment VARCHAR(255) DEFAULT NULL,
+    address VARCHAR(45) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY unique_isolation (username, router_id, list_name),
+    INDEX idx_router (router_id)
+);
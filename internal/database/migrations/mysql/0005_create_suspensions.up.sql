@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS suspensions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    strategy VARCHAR(20) NOT NULL,
+    profile VARCHAR(100) DEFAULT NULL,
+    previous_profile VARCHAR(100) NOT NULL,
+    previous_disabled BOOLEAN DEFAULT FALSE,
+    comment VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY unique_suspension (username, router_id),
+    INDEX idx_router (router_id)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS pppoe_sessions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    address VARCHAR(45) DEFAULT NULL,
+    caller_id VARCHAR(64) DEFAULT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME DEFAULT NULL,
+    duration INT DEFAULT NULL,
+
+    INDEX idx_user_started (username, started_at),
+    INDEX idx_router_open (router_id, ended_at)
+);
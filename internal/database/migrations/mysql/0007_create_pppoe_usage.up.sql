@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS pppoe_usage (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    rx_bytes BIGINT NOT NULL DEFAULT 0,
+    tx_bytes BIGINT NOT NULL DEFAULT 0,
+
+    UNIQUE KEY unique_usage (username, router_id, period_start),
+    INDEX idx_router_period (router_id, period_start)
+);
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS fup_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    profile VARCHAR(100) NOT NULL,
+    quota_bytes BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    throttle_profile VARCHAR(100) DEFAULT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    enabled BOOLEAN DEFAULT TRUE,
+
+    UNIQUE KEY unique_profile (profile)
+);
+
+CREATE TABLE IF NOT EXISTS fup_states (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    rule_id INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    original_profile VARCHAR(100) NOT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    cycle_start DATETIME NOT NULL,
+    applied_at DATETIME NOT NULL,
+
+    UNIQUE KEY unique_state (username, router_id)
+);
+
+CREATE TABLE IF NOT EXISTS fup_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    rule_id INT NOT NULL,
+    event VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    usage_bytes BIGINT NOT NULL,
+    quota_bytes BIGINT NOT NULL,
+    created_at DATETIME NOT NULL,
+
+    INDEX idx_user_created (username, created_at)
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    action VARCHAR(32) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL DEFAULT 0,
+    profile VARCHAR(100) DEFAULT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    comment VARCHAR(255) DEFAULT NULL,
+    cron VARCHAR(100) DEFAULT NULL,
+    run_at DATETIME DEFAULT NULL,
+    next_run_at DATETIME DEFAULT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    runs INT NOT NULL DEFAULT 0,
+    last_run_at DATETIME DEFAULT NULL,
+    last_error TEXT,
+    created_at DATETIME NOT NULL,
+
+    INDEX idx_due (status, next_run_at),
+    INDEX idx_user (username)
+);
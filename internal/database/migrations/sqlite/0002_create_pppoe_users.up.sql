@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS pppoe_users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    profile VARCHAR(100) DEFAULT 'default',
+    is_enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (username, router_id)
+);
+CREATE INDEX IF NOT EXISTS idx_pppoe_users_router ON pppoe_users (router_id);
+CREATE INDEX IF NOT EXISTS idx_pppoe_users_enabled ON pppoe_users (is_enabled);
+CREATE INDEX IF NOT EXISTS idx_pppoe_users_username ON pppoe_users (username);
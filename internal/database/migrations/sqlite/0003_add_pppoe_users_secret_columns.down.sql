@@ -0,0 +1,8 @@
+ALTER TABLE pppoe_users DROP COLUMN remote_address;
+ALTER TABLE pppoe_users DROP COLUMN deleted_at;
+ALTER TABLE pppoe_users DROP COLUMN local_address;
+ALTER TABLE pppoe_users DROP COLUMN caller_id;
+ALTER TABLE pppoe_users DROP COLUMN service;
+ALTER TABLE pppoe_users DROP COLUMN comment;
+ALTER TABLE pppoe_users DROP COLUMN last_logged_out;
+ALTER TABLE pppoe_users DROP COLUMN last_caller_id;
@@ -0,0 +1,8 @@
+ALTER TABLE pppoe_users ADD COLUMN remote_address VARCHAR(45) DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN deleted_at DATETIME DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN local_address VARCHAR(45) DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN caller_id VARCHAR(64) DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN service VARCHAR(20) DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN comment VARCHAR(255) DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN last_logged_out DATETIME DEFAULT NULL;
+ALTER TABLE pppoe_users ADD COLUMN last_caller_id VARCHAR(64) DEFAULT NULL;
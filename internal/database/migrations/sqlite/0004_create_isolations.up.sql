@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS isolations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    list_name VARCHAR(100) NOT NULL,
+    comment VARCHAR(255) DEFAULT NULL,
+    address VARCHAR(45) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (username, router_id, list_name)
+);
+CREATE INDEX IF NOT EXISTS idx_isolations_router ON isolations (router_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS suspensions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    strategy VARCHAR(20) NOT NULL,
+    profile VARCHAR(100) DEFAULT NULL,
+    previous_profile VARCHAR(100) NOT NULL,
+    previous_disabled BOOLEAN DEFAULT FALSE,
+    comment VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (username, router_id)
+);
+CREATE INDEX IF NOT EXISTS idx_suspensions_router ON suspensions (router_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS pppoe_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    address VARCHAR(45) DEFAULT NULL,
+    caller_id VARCHAR(64) DEFAULT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME DEFAULT NULL,
+    duration INT DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_started ON pppoe_sessions (username, started_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_router_open ON pppoe_sessions (router_id, ended_at);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS pppoe_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    period_start DATETIME NOT NULL,
+    rx_bytes BIGINT NOT NULL DEFAULT 0,
+    tx_bytes BIGINT NOT NULL DEFAULT 0,
+
+    UNIQUE (username, router_id, period_start)
+);
+CREATE INDEX IF NOT EXISTS idx_usage_router_period ON pppoe_usage (router_id, period_start);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fup_events;
+DROP TABLE IF EXISTS fup_states;
+DROP TABLE IF EXISTS fup_rules;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS fup_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    profile VARCHAR(100) NOT NULL UNIQUE,
+    quota_bytes BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    throttle_profile VARCHAR(100) DEFAULT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    enabled BOOLEAN DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS fup_states (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    rule_id INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    original_profile VARCHAR(100) NOT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    cycle_start DATETIME NOT NULL,
+    applied_at DATETIME NOT NULL,
+
+    UNIQUE (username, router_id)
+);
+
+CREATE TABLE IF NOT EXISTS fup_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL,
+    rule_id INT NOT NULL,
+    event VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    usage_bytes BIGINT NOT NULL,
+    quota_bytes BIGINT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_fup_events_user_created ON fup_events (username, created_at);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    action VARCHAR(32) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    router_id INT NOT NULL DEFAULT 0,
+    profile VARCHAR(100) DEFAULT NULL,
+    address_list VARCHAR(100) DEFAULT NULL,
+    comment VARCHAR(255) DEFAULT NULL,
+    cron VARCHAR(100) DEFAULT NULL,
+    run_at DATETIME DEFAULT NULL,
+    next_run_at DATETIME DEFAULT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    runs INT NOT NULL DEFAULT 0,
+    last_run_at DATETIME DEFAULT NULL,
+    last_error TEXT,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_due ON scheduled_jobs (status, next_run_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_user ON scheduled_jobs (username);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS async_jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    router_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    progress INT NOT NULL DEFAULT 0,
+    result TEXT,
+    error TEXT,
+    created_at DATETIME NOT NULL,
+    started_at DATETIME DEFAULT NULL,
+    finished_at DATETIME DEFAULT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_async_jobs_status_created ON async_jobs (status, created_at);
@@ -0,0 +1 @@
+ALTER TABLE fup_states DROP COLUMN throttle_profile;
@@ -0,0 +1,2 @@
+-- Profile a throttled subscriber was moved to, so a restore can tell whether it was changed since
+ALTER TABLE fup_states ADD COLUMN throttle_profile VARCHAR(100) DEFAULT NULL;
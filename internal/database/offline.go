@@ -0,0 +1,215 @@
+package database
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// monitorInterval is how often the database is pinged, to fall back to the
+// snapshot when it goes away and to switch back when it returns
+const monitorInterval = 15 * time.Second
+
+// OfflineStore serves routers, users, suspensions and FUP states from a snapshot
+// while the database is unreachable. Everything else goes to the underlying
+// store and fails until it is back (see OfflineUnavailable).
+type OfflineStore struct {
+	Store
+	snap *Snapshot
+}
+
+// NewOfflineStore wraps the unreachable store with a snapshot
+func NewOfflineStore(st Store, snap *Snapshot) *OfflineStore {
+	return &OfflineStore{Store: st, snap: snap}
+}
+
+func (o *OfflineStore) GetAllRouters() ([]models.Router, error) {
+	return append([]models.Router(nil), o.snap.Routers...), nil
+}
+
+func (o *OfflineStore) GetUsersByRouter(routerID int) (map[string]DBUser, error) {
+	users := make(map[string]DBUser)
+	for _, u := range o.find(func(u DBUser) bool { return u.RouterID == routerID }) {
+		users[u.Username] = u
+	}
+	return users, nil
+}
+
+func (o *OfflineStore) GetUser(routerID int, username string) (*DBUser, error) {
+	found := o.find(func(u DBUser) bool { return u.RouterID == routerID && u.Username == username })
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return &found[0], nil
+}
+
+func (o *OfflineStore) FindUserRouterIDs(username string) ([]int, error) {
+	ids := make([]int, 0)
+	for _, u := range o.find(func(u DBUser) bool { return u.Username == username }) {
+		ids = append(ids, u.RouterID)
+	}
+	return ids, nil
+}
+
+func (o *OfflineStore) FindUsers(username string) ([]DBUser, error) {
+	return o.find(func(u DBUser) bool { return u.Username == username }), nil
+}
+
+func (o *OfflineStore) FindUsersByAddress(ip string) ([]DBUser, error) {
+	return o.find(func(u DBUser) bool { return u.RemoteAddress == ip }), nil
+}
+
+func (o *OfflineStore) FindUsersByCallerID(mac string) ([]DBUser, error) {
+	normalize := func(s string) string { return strings.ToUpper(strings.ReplaceAll(s, "-", ":")) }
+	return o.find(func(u DBUser) bool {
+		return (u.CallerID != "" && normalize(u.CallerID) == mac) || (u.LastCallerID != "" && normalize(u.LastCallerID) == mac)
+	}), nil
+}
+
+func (o *OfflineStore) GetStaticAddresses() (map[string][]DBUser, error) {
+	addresses := make(map[string][]DBUser)
+	for _, u := range o.find(func(u DBUser) bool { return u.RemoteAddress != "" }) {
+		addresses[u.RemoteAddress] = append(addresses[u.RemoteAddress], u)
+	}
+	return addresses, nil
+}
+
+func (o *OfflineStore) GetSuspension(username string, routerID int) (*models.Suspension, error) {
+	for _, s := range o.snap.Suspensions {
+		if s.Username == username && s.RouterID == routerID {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (o *OfflineStore) GetSuspensionsByRouter(routerID int) (map[string]models.Suspension, error) {
+	suspensions := make(map[string]models.Suspension)
+	for _, s := range o.snap.Suspensions {
+		if s.RouterID == routerID {
+			suspensions[s.Username] = s
+		}
+	}
+	return suspensions, nil
+}
+
+func (o *OfflineStore) GetFUPStates() ([]models.FUPState, error) {
+	return append([]models.FUPState(nil), o.snap.FUPStates...), nil
+}
+
+func (o *OfflineStore) find(match func(DBUser) bool) []DBUser {
+	users := make([]DBUser, 0)
+	for _, u := range o.snap.Users {
+		if match(u) {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// OfflineUnavailable lists the operations that write to the database, and so
+// fail (or, for FUP enforcement, pause) while it is unreachable
+var OfflineUnavailable = []string{"suspend", "resume", "fup", "sync", "schedules", "jobs", "fup_rules", "session_history", "usage"}
+
+// OfflineState describes whether the engine is running without its database
+type OfflineState struct {
+	Degraded    bool       `json:"degraded"`
+	Error       string     `json:"error,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+	SnapshotAt  *time.Time `json:"snapshot_taken_at,omitempty"` // nil: no snapshot, inventory is empty
+	Unavailable []string   `json:"unavailable,omitempty"`       // OfflineUnavailable while degraded
+}
+
+var offline struct {
+	sync.RWMutex
+	state   OfflineState
+	primary Store // the real store while Default is an OfflineStore
+}
+
+// Offline returns the current degraded state
+func Offline() OfflineState {
+	offline.RLock()
+	defer offline.RUnlock()
+	return offline.state
+}
+
+// goOffline switches Default to the snapshot (if one can be read) after the database failed
+func goOffline(cause error) {
+	offline.Lock()
+	defer offline.Unlock()
+	if offline.state.Degraded {
+		return
+	}
+
+	now := time.Now()
+	primary := current()
+	offline.primary = primary
+	offline.state = OfflineState{Degraded: true, Error: cause.Error(), Since: &now, Unavailable: OfflineUnavailable}
+
+	snap, err := ReadSnapshot(SnapshotPath, SnapshotKey)
+	if err != nil {
+		logger.Warn("No usable snapshot - running without router inventory", zap.Error(err))
+		return
+	}
+	offline.state.SnapshotAt = &snap.TakenAt
+	Use(NewOfflineStore(primary, snap))
+	logger.Warn("Running from snapshot", zap.Time("taken_at", snap.TakenAt), zap.Int("routers", len(snap.Routers)), zap.Int("users", len(snap.Users)),
+		zap.Strings("unavailable", OfflineUnavailable))
+}
+
+// goOnline restores the real store once it answers again
+func goOnline() {
+	offline.Lock()
+	defer offline.Unlock()
+	if !offline.state.Degraded {
+		return
+	}
+	Use(offline.primary)
+	offline.primary = nil
+	offline.state = OfflineState{}
+}
+
+// StartMonitor pings the database in the background. When it stops answering the
+// engine falls back to the snapshot; when it returns, pending migrations run,
+// Default is switched back and onRestore is called to reconcile what happened meanwhile.
+func StartMonitor(onRestore func()) {
+	go func() {
+		for {
+			time.Sleep(monitorInterval)
+
+			offline.RLock()
+			degraded, primary := offline.state.Degraded, offline.primary
+			offline.RUnlock()
+
+			if !degraded {
+				if err := current().Ping(); err != nil {
+					logger.Error("Database unreachable - switching to offline mode", zap.Error(err))
+					goOffline(err)
+				}
+				continue
+			}
+
+			if err := primary.Ping(); err != nil {
+				continue
+			}
+			logger.Info("Database connection restored")
+			if AutoMigrate {
+				if _, err := primary.MigrateUp(0); err != nil {
+					logger.Error("Failed to migrate database", zap.Error(err))
+				}
+			}
+			goOnline()
+			if onRestore != nil {
+				onRestore()
+			}
+			if err := SaveSnapshot(); err != nil && err != errNoSnapshotKey {
+				logger.Error("Failed to save snapshot", zap.Error(err))
+			}
+		}
+	}()
+}
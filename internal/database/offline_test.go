@@ -0,0 +1,130 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedInventory(t *testing.T, st *SQLStore) {
+	t.Helper()
+	_, err := st.db.Exec("INSERT INTO routers (id, name, host, port, username, password) VALUES (1, 'core', '10.0.0.1', 8728, 'api', 's3cret')")
+	require.NoError(t, err)
+	_, err = st.SyncUsers(1, []models.PPPoESecret{
+		{Name: "alice", Profile: "10M", RemoteAddress: "10.10.0.5", SecretDetails: models.SecretDetails{LastCallerID: "aa-bb-cc-dd-ee-01"}},
+		{Name: "bob", Profile: "20M"},
+	}, models.SyncDeleteMark)
+	require.NoError(t, err)
+	require.NoError(t, st.SaveSuspension(models.Suspension{
+		Username: "bob", RouterID: 1, Strategy: models.SuspendDisable, PreviousProfile: "20M", CreatedAt: time.Now(),
+	}))
+	require.NoError(t, st.SaveFUPState(models.FUPState{
+		Username: "alice", RouterID: 1, RuleID: 1, Action: "throttle", OriginalProfile: "10M", ThrottleProfile: "1M",
+		CycleStart: time.Now(), AppliedAt: time.Now(),
+	}))
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	st := newTestStore(t)
+	seedInventory(t, st)
+	path := filepath.Join(t.TempDir(), "netengine.snapshot")
+
+	snap, err := TakeSnapshot(st)
+	require.NoError(t, err)
+	require.NoError(t, WriteSnapshot(path, "passphrase", snap))
+
+	_, err = ReadSnapshot(path, "wrong")
+	assert.Error(t, err)
+
+	read, err := ReadSnapshot(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", read.Routers[0].Password)
+	assert.Len(t, read.Users, 2)
+	assert.Len(t, read.Suspensions, 1)
+	assert.Len(t, read.FUPStates, 1)
+	assert.True(t, snap.TakenAt.Equal(read.TakenAt))
+
+	// A fresh salt each time, and the header is authenticated
+	first, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, WriteSnapshot(path, "passphrase", snap))
+	second, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, snapshotMagic, string(second[:len(snapshotMagic)]))
+	assert.NotEqual(t, first[:len(snapshotMagic)+snapshotSaltSize], second[:len(snapshotMagic)+snapshotSaltSize])
+	second[len(snapshotMagic)] ^= 1
+	require.NoError(t, os.WriteFile(path, second, 0600))
+	_, err = ReadSnapshot(path, "passphrase")
+	assert.Error(t, err)
+}
+
+func TestReadSnapshotRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"no header": []byte(`{"routers":[]}`),
+		"truncated": []byte(snapshotMagic + "salt"),
+	} {
+		path := filepath.Join(dir, "netengine.snapshot")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		_, err := ReadSnapshot(path, "passphrase")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestOfflineFallback(t *testing.T) {
+	st := newTestStore(t)
+	seedInventory(t, st)
+
+	SnapshotPath, SnapshotKey = filepath.Join(t.TempDir(), "netengine.snapshot"), "passphrase"
+	prev := Use(st)
+	t.Cleanup(func() {
+		goOnline()
+		Use(prev)
+		SnapshotPath, SnapshotKey = "netengine.snapshot", ""
+	})
+	require.NoError(t, SaveSnapshot())
+
+	// The database "goes away": reads through Live come from the snapshot
+	goOffline(assert.AnError)
+	state := Offline()
+	assert.True(t, state.Degraded)
+	assert.NotNil(t, state.SnapshotAt)
+	assert.Contains(t, state.Unavailable, "suspend")
+	require.NoError(t, SaveSnapshot(), "no snapshot is taken from a snapshot")
+
+	_, err := st.db.Exec("DELETE FROM pppoe_users")
+	require.NoError(t, err)
+
+	routers, err := Live.GetAllRouters()
+	require.NoError(t, err)
+	require.Len(t, routers, 1)
+	users, err := Live.GetUsersByRouter(1)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	found, err := Live.FindUsersByAddress("10.10.0.5")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "alice", found[0].Username)
+	found, err = Live.FindUsersByCallerID("AA:BB:CC:DD:EE:01")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "alice", found[0].Username)
+	suspensions, err := Live.GetSuspensionsByRouter(1)
+	require.NoError(t, err)
+	assert.Contains(t, suspensions, "bob")
+	states, err := Live.GetFUPStates()
+	require.NoError(t, err)
+	assert.Len(t, states, 1)
+
+	goOnline()
+	assert.False(t, Offline().Degraded)
+	users, err = Live.GetUsersByRouter(1)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
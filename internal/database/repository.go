@@ -7,8 +7,8 @@ import (
 	"database/sql"
 )
 
-func GetAllRouters() ([]models.Router, error) {
-	rows, err := DB.Query("SELECT id, name, host, port, username, password FROM routers")
+func (st *SQLStore) GetAllRouters() ([]models.Router, error) {
+	rows, err := st.db.Query("SELECT id, name, host, port, username, password FROM routers")
 	if err != nil {
 		logger.Error("Failed to fetch routers", zap.Error(err))
 		return nil, err
@@ -28,18 +28,27 @@ func GetAllRouters() ([]models.Router, error) {
 	return routers, nil
 }
 
+// SaveRouter inserts a router with its ID, or updates the row that has it
+func (st *SQLStore) SaveRouter(r models.Router) error {
+	query := `
+		INSERT INTO routers (id, name, host, port, username, password)
+		VALUES (?, ?, ?, ?, ?, ?)
+	` + st.upsert("id", "name", "host", "port", "username", "password")
+	_, err := st.db.Exec(query, r.ID, r.Name, r.Host, r.Port, r.Username, r.Password)
+	if err != nil {
+		logger.Error("Failed to save router", zap.Int("id", r.ID), zap.Error(err))
+	}
+	return err
+}
+
 // UpsertUser inserts or updates a PPPoE user
-func UpsertUser(username string, routerID int, profile string, remoteAddress string, isEnabled bool) error {
+func (st *SQLStore) UpsertUser(username string, routerID int, profile string, remoteAddress string, isEnabled bool) error {
 	query := `
 		INSERT INTO pppoe_users (username, router_id, profile, remote_address, is_enabled)
 		VALUES (?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE
-			profile = VALUES(profile),
-			remote_address = VALUES(remote_address),
-			is_enabled = VALUES(is_enabled),
-			updated_at = CURRENT_TIMESTAMP
-	`
-	_, err := DB.Exec(query, username, routerID, profile, remoteAddress, isEnabled)
+	` + st.upsert("username, router_id", "profile", "remote_address", "is_enabled") +
+		", deleted_at = NULL, updated_at = CURRENT_TIMESTAMP"
+	_, err := st.db.Exec(query, username, routerID, profile, remoteAddress, isEnabled)
 	if err != nil {
 		logger.Error("Failed to upsert user", zap.String("user", username), zap.Error(err))
 	}
@@ -48,36 +57,138 @@ func UpsertUser(username string, routerID int, profile string, remoteAddress str
 
 // DBUser represents a user record from the database
 type DBUser struct {
+	Username      string
+	RouterID      int
 	Profile       string
 	RemoteAddress string
 	IsEnabled     bool
+	models.SecretDetails
+}
+
+const userColumns = `username, router_id, profile, remote_address, is_enabled,
+	local_address, caller_id, service, comment, last_logged_out, last_caller_id`
+
+const selectUsers = "SELECT " + userColumns + " FROM pppoe_users WHERE deleted_at IS NULL"
+
+// scanUser scans userColumns, followed by any extra columns into extra
+func scanUser(row scanner, extra ...interface{}) (DBUser, error) {
+	var u DBUser
+	var profile, remoteAddress, localAddress, callerID, service, comment, lastCallerID sql.NullString // Handle potential NULLs
+	var lastLoggedOut sql.NullTime
+	dest := []interface{}{&u.Username, &u.RouterID, &profile, &remoteAddress, &u.IsEnabled,
+		&localAddress, &callerID, &service, &comment, &lastLoggedOut, &lastCallerID}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return u, err
+	}
+	u.Profile = profile.String
+	u.RemoteAddress = remoteAddress.String
+	u.SecretDetails = models.SecretDetails{
+		LocalAddress:  localAddress.String,
+		CallerID:      callerID.String,
+		Service:       service.String,
+		Comment:       comment.String,
+		LastLoggedOut: nullTime(lastLoggedOut),
+		LastCallerID:  lastCallerID.String,
+	}
+	return u, nil
+}
+
+// GetUsersByRouter fetches all users for a specific router (including disabled ones, excluding deleted ones)
+func (st *SQLStore) GetUsersByRouter(routerID int) (map[string]DBUser, error) {
+	list, err := st.findUsers("router_id = ?", routerID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]DBUser, len(list)) // username -> DBUser
+	for _, u := range list {
+		users[u.Username] = u
+	}
+	return users, nil
+}
+
+// GetUser fetches a single user of a router, or nil if it is not provisioned there
+func (st *SQLStore) GetUser(routerID int, username string) (*DBUser, error) {
+	u, err := scanUser(st.db.QueryRow(selectUsers+" AND router_id = ? AND username = ?", routerID, username))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to fetch user", zap.String("user", username), zap.Error(err))
+		return nil, err
+	}
+	return &u, nil
 }
 
-// GetUsersByRouter fetches all users for a specific router (including disabled ones)
-func GetUsersByRouter(routerID int) (map[string]DBUser, error) {
-	rows, err := DB.Query("SELECT username, profile, remote_address, is_enabled FROM pppoe_users WHERE router_id = ?", routerID)
+// FindUserRouterIDs returns the routers a username is provisioned on
+func (st *SQLStore) FindUserRouterIDs(username string) ([]int, error) {
+	rows, err := st.db.Query("SELECT router_id FROM pppoe_users WHERE username = ? AND deleted_at IS NULL", username)
 	if err != nil {
-		logger.Error("Failed to fetch users by router", zap.Int("router_id", routerID), zap.Error(err))
+		logger.Error("Failed to look up user routers", zap.String("user", username), zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
 
-	users := make(map[string]DBUser) // username -> DBUser
+	ids := make([]int, 0)
 	for rows.Next() {
-		var username, profile string
-		var remoteAddress sql.NullString // Handle potential NULLs
-		var isEnabled bool
-		
-		if err := rows.Scan(&username, &profile, &remoteAddress, &isEnabled); err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
 			logger.Error("Scan error", zap.Error(err))
 			continue
 		}
-		users[username] = DBUser{
-			Profile:       profile,
-			RemoteAddress: remoteAddress.String,
-			IsEnabled:     isEnabled,
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// FindUsers returns a username's records on every router it is provisioned on
+func (st *SQLStore) FindUsers(username string) ([]DBUser, error) {
+	return st.findUsers("username = ?", username)
+}
+
+// FindUsersByAddress returns the users whose static remote address is ip
+func (st *SQLStore) FindUsersByAddress(ip string) ([]DBUser, error) {
+	return st.findUsers("remote_address = ?", ip)
+}
+
+// FindUsersByCallerID returns the users bound to or last dialed from a MAC,
+// given upper case with colons; stored values may use dashes or lower case
+func (st *SQLStore) FindUsersByCallerID(mac string) ([]DBUser, error) {
+	return st.findUsers("(UPPER(REPLACE(caller_id, '-', ':')) = ? OR UPPER(REPLACE(last_caller_id, '-', ':')) = ?)", mac, mac)
+}
+
+func (st *SQLStore) findUsers(where string, args ...interface{}) ([]DBUser, error) {
+	rows, err := st.db.Query(selectUsers+" AND "+where, args...)
+	if err != nil {
+		logger.Error("Failed to find users", zap.String("where", where), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]DBUser, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
 		}
+		users = append(users, u)
 	}
 
 	return users, nil
 }
+
+// GetStaticAddresses maps every pinned remote address to the users it is assigned to
+func (st *SQLStore) GetStaticAddresses() (map[string][]DBUser, error) {
+	users, err := st.findUsers("remote_address IS NOT NULL AND remote_address <> ''")
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[string][]DBUser)
+	for _, u := range users {
+		addresses[u.RemoteAddress] = append(addresses[u.RemoteAddress], u)
+	}
+	return addresses, nil
+}
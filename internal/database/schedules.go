@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// CreateScheduledJob stores a new job and returns its ID
+func (st *SQLStore) CreateScheduledJob(j models.ScheduledJob) (int64, error) {
+	query := `
+		INSERT INTO scheduled_jobs (action, username, router_id, profile, address_list, comment, cron, run_at, next_run_at, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := st.db.Exec(query, j.Action, j.User, j.RouterID, j.Profile, j.List, j.Comment, j.Cron, j.RunAt, j.NextRunAt, j.Status, j.CreatedAt)
+	if err != nil {
+		logger.Error("Failed to create scheduled job", zap.String("user", j.User), zap.Error(err))
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// UpdateScheduledJob saves the outcome of a run. Jobs cancelled while they
+// were running stay cancelled; false is returned for them.
+func (st *SQLStore) UpdateScheduledJob(j models.ScheduledJob) (bool, error) {
+	query := `
+		UPDATE scheduled_jobs
+		SET next_run_at = ?, status = ?, attempts = ?, runs = ?, last_run_at = ?, last_error = ?
+		WHERE id = ? AND status = ?
+	`
+	res, err := st.db.Exec(query, j.NextRunAt, j.Status, j.Attempts, j.Runs, j.LastRunAt, j.LastError, j.ID, models.SchedulePending)
+	if err != nil {
+		logger.Error("Failed to update scheduled job", zap.Int64("id", j.ID), zap.Error(err))
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// CancelScheduledJob cancels a pending job. It returns false if the job does
+// not exist or already finished.
+func (st *SQLStore) CancelScheduledJob(id int64) (bool, error) {
+	res, err := st.db.Exec("UPDATE scheduled_jobs SET status = ?, next_run_at = NULL WHERE id = ? AND status = ?",
+		models.ScheduleCancelled, id, models.SchedulePending)
+	if err != nil {
+		logger.Error("Failed to cancel scheduled job", zap.Int64("id", id), zap.Error(err))
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+const selectScheduledJobs = `
+	SELECT id, action, username, router_id, profile, address_list, comment, cron, run_at, next_run_at,
+		status, attempts, runs, last_run_at, last_error, created_at
+	FROM scheduled_jobs`
+
+// GetScheduledJob fetches one job, or nil if it does not exist
+func (st *SQLStore) GetScheduledJob(id int64) (*models.ScheduledJob, error) {
+	j, err := scanScheduledJob(st.db.QueryRow(selectScheduledJobs+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to fetch scheduled job", zap.Int64("id", id), zap.Error(err))
+		return nil, err
+	}
+	return j, nil
+}
+
+// GetScheduledJobs lists jobs, optionally filtered by status and username
+func (st *SQLStore) GetScheduledJobs(status, username string) ([]models.ScheduledJob, error) {
+	where, args := " WHERE 1=1", []interface{}{}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+	if username != "" {
+		where += " AND username = ?"
+		args = append(args, username)
+	}
+	return st.queryScheduledJobs(selectScheduledJobs+where+" ORDER BY id DESC", args...)
+}
+
+// GetDueJobs fetches pending jobs whose next run is at or before now
+func (st *SQLStore) GetDueJobs(now time.Time) ([]models.ScheduledJob, error) {
+	return st.queryScheduledJobs(selectScheduledJobs+" WHERE status = ? AND next_run_at <= ? ORDER BY next_run_at",
+		models.SchedulePending, now)
+}
+
+func (st *SQLStore) queryScheduledJobs(query string, args ...interface{}) ([]models.ScheduledJob, error) {
+	rows, err := st.db.Query(query, args...)
+	if err != nil {
+		logger.Error("Failed to fetch scheduled jobs", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]models.ScheduledJob, 0)
+	for rows.Next() {
+		j, err := scanScheduledJob(rows)
+		if err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, nil
+}
+
+func scanScheduledJob(row scanner) (*models.ScheduledJob, error) {
+	var j models.ScheduledJob
+	var profile, list, comment, cron, lastError sql.NullString
+	var runAt, nextRunAt, lastRunAt sql.NullTime
+	if err := row.Scan(&j.ID, &j.Action, &j.User, &j.RouterID, &profile, &list, &comment, &cron, &runAt, &nextRunAt,
+		&j.Status, &j.Attempts, &j.Runs, &lastRunAt, &lastError, &j.CreatedAt); err != nil {
+		return nil, err
+	}
+	j.Profile = profile.String
+	j.List = list.String
+	j.Comment = comment.String
+	j.Cron = cron.String
+	j.LastError = lastError.String
+	j.RunAt = nullTime(runAt)
+	j.NextRunAt = nullTime(nextRunAt)
+	j.LastRunAt = nullTime(lastRunAt)
+	return &j, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
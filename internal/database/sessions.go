@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// OpenSession records the start of a PPP session
+func (st *SQLStore) OpenSession(s models.Session) error {
+	_, err := st.db.Exec(
+		"INSERT INTO pppoe_sessions (username, router_id, address, caller_id, started_at) VALUES (?, ?, ?, ?, ?)",
+		s.Username, s.RouterID, s.Address, s.CallerID, s.StartedAt,
+	)
+	if err != nil {
+		logger.Error("Failed to open session", zap.String("user", s.Username), zap.Error(err))
+	}
+	return err
+}
+
+// CloseSession ends the open session of a user on a router at the given address
+func (st *SQLStore) CloseSession(routerID int, username, address string, endedAt time.Time) error {
+	var id int64
+	var startedAt time.Time
+	err := st.db.QueryRow(
+		"SELECT id, started_at FROM pppoe_sessions WHERE router_id = ? AND username = ? AND address = ? AND ended_at IS NULL ORDER BY started_at DESC LIMIT 1",
+		routerID, username, address,
+	).Scan(&id, &startedAt)
+	if err == sql.ErrNoRows {
+		return nil // Opened before history was recorded
+	}
+	if err != nil {
+		logger.Error("Failed to find open session", zap.String("user", username), zap.Error(err))
+		return err
+	}
+
+	return st.closeSessionByID(id, startedAt, endedAt)
+}
+
+func (st *SQLStore) closeSessionByID(id int64, startedAt, endedAt time.Time) error {
+	duration := int64(endedAt.Sub(startedAt).Seconds())
+	if duration < 0 {
+		duration = 0
+	}
+	_, err := st.db.Exec("UPDATE pppoe_sessions SET ended_at = ?, duration = ? WHERE id = ?", endedAt, duration, id)
+	if err != nil {
+		logger.Error("Failed to close session", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// CloseSessions ends open sessions by ID, e.g. ones that ended while the engine was down
+func (st *SQLStore) CloseSessions(sessions []models.Session, endedAt time.Time) {
+	for _, s := range sessions {
+		st.closeSessionByID(s.ID, s.StartedAt, endedAt)
+	}
+}
+
+const selectSessions = "SELECT id, username, router_id, address, caller_id, started_at, ended_at, duration FROM pppoe_sessions"
+
+// GetOpenSessions fetches the sessions of a router that have not ended yet
+func (st *SQLStore) GetOpenSessions(routerID int) ([]models.Session, error) {
+	return st.querySessions(selectSessions+" WHERE router_id = ? AND ended_at IS NULL", routerID)
+}
+
+// GetUserSessions fetches a user's sessions overlapping [from, to), newest first.
+// routerID 0 means all routers.
+func (st *SQLStore) GetUserSessions(username string, routerID int, from, to time.Time, limit int) ([]models.Session, error) {
+	query := selectSessions + " WHERE username = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)"
+	args := []interface{}{username, to, from}
+	if routerID != 0 {
+		query += " AND router_id = ?"
+		args = append(args, routerID)
+	}
+	query += " ORDER BY started_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	return st.querySessions(query, args...)
+}
+
+func (st *SQLStore) querySessions(query string, args ...interface{}) ([]models.Session, error) {
+	rows, err := st.db.Query(query, args...)
+	if err != nil {
+		logger.Error("Failed to fetch sessions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var s models.Session
+		var address, callerID sql.NullString
+		var endedAt sql.NullTime
+		var duration sql.NullInt64
+		if err := rows.Scan(&s.ID, &s.Username, &s.RouterID, &address, &callerID, &s.StartedAt, &endedAt, &duration); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		s.Address = address.String
+		s.CallerID = callerID.String
+		if endedAt.Valid {
+			s.EndedAt = &endedAt.Time
+			s.Duration = duration.Int64
+		} else {
+			s.Duration = int64(now.Sub(s.StartedAt).Seconds())
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
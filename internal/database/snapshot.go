@@ -0,0 +1,203 @@
+package database
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Snapshot settings, set by Configure. Snapshots hold router credentials, so they
+// are only written when SnapshotKey is set (any passphrase; scrypt turns it into an AES-256 key).
+var (
+	SnapshotPath = "netengine.snapshot"
+	SnapshotKey  string
+)
+
+// snapshotInterval is how often the snapshot is refreshed while the database is up
+const snapshotInterval = 5 * time.Minute
+
+var errNoSnapshotKey = errors.New("SNAPSHOT_KEY not set")
+
+// Snapshot files start with snapshotMagic and a random scrypt salt; both are
+// authenticated with the contents. Files without the magic are rejected.
+const (
+	snapshotMagic    = "NESNAP2\n"
+	snapshotSaltSize = 16
+)
+
+// Snapshot is the router inventory and user metadata needed to run without the
+// database, with suspensions and FUP states so lookups and reconciliation
+// still leave those users alone
+type Snapshot struct {
+	TakenAt     time.Time           `json:"taken_at"`
+	Routers     []models.Router     `json:"routers"`
+	Users       []DBUser            `json:"users"`
+	Suspensions []models.Suspension `json:"suspensions"`
+	FUPStates   []models.FUPState   `json:"fup_states"`
+}
+
+// TakeSnapshot reads the inventory and every router's users from a store
+func TakeSnapshot(st Store) (*Snapshot, error) {
+	routers, err := st.GetAllRouters()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{TakenAt: time.Now(), Routers: routers, Users: make([]DBUser, 0), Suspensions: make([]models.Suspension, 0)}
+	for _, r := range routers {
+		users, err := st.GetUsersByRouter(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			snap.Users = append(snap.Users, u)
+		}
+		suspensions, err := st.GetSuspensionsByRouter(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range suspensions {
+			snap.Suspensions = append(snap.Suspensions, s)
+		}
+	}
+	if snap.FUPStates, err = st.GetFUPStates(); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// WriteSnapshot encrypts a snapshot with AES-GCM and replaces the file atomically
+func WriteSnapshot(path, key string, snap *Snapshot) error {
+	if key == "" {
+		return errNoSnapshotKey
+	}
+	plain, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, len(snapshotMagic)+snapshotSaltSize)
+	copy(header, snapshotMagic)
+	if _, err := io.ReadFull(rand.Reader, header[len(snapshotMagic):]); err != nil {
+		return err
+	}
+	gcm, err := snapshotCipher(key, header[len(snapshotMagic):])
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := gcm.Seal(append(header, nonce...), nonce, plain, header)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after the rename
+	if _, err := tmp.Write(sealed); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path) // CreateTemp already made it 0600
+}
+
+// ReadSnapshot decrypts a snapshot written by WriteSnapshot
+func ReadSnapshot(path, key string) (*Snapshot, error) {
+	if key == "" {
+		return nil, errNoSnapshotKey
+	}
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(sealed, []byte(snapshotMagic)) {
+		return nil, errors.New("not a snapshot file")
+	}
+	if len(sealed) < len(snapshotMagic)+snapshotSaltSize {
+		return nil, errors.New("snapshot file is truncated")
+	}
+	header, sealed := sealed[:len(snapshotMagic)+snapshotSaltSize], sealed[len(snapshotMagic)+snapshotSaltSize:]
+	gcm, err := snapshotCipher(key, header[len(snapshotMagic):])
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("snapshot file is truncated")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], header)
+	if err != nil {
+		return nil, errors.New("snapshot cannot be decrypted (wrong SNAPSHOT_KEY or corrupt file)")
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(plain, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// snapshotCipher derives the AES-256 key from the passphrase and salt with scrypt
+func snapshotCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SaveSnapshot writes a fresh snapshot of Default, unless running from one
+func SaveSnapshot() error {
+	if SnapshotKey == "" {
+		return errNoSnapshotKey
+	}
+	if Offline().Degraded {
+		return nil // Nothing newer than what is on disk
+	}
+	snap, err := TakeSnapshot(current())
+	if err != nil {
+		return err
+	}
+	if err := WriteSnapshot(SnapshotPath, SnapshotKey, snap); err != nil {
+		return err
+	}
+	logger.Info("Snapshot saved", zap.String("path", SnapshotPath), zap.Int("routers", len(snap.Routers)), zap.Int("users", len(snap.Users)),
+		zap.Int("suspensions", len(snap.Suspensions)), zap.Int("fup_states", len(snap.FUPStates)))
+	return nil
+}
+
+// StartSnapshots refreshes the snapshot now and every snapshotInterval
+func StartSnapshots() {
+	if SnapshotKey == "" {
+		logger.Warn("SNAPSHOT_KEY not set - offline mode will have no router inventory if the database is down")
+		return
+	}
+	go func() {
+		for {
+			if err := SaveSnapshot(); err != nil {
+				logger.Error("Failed to save snapshot", zap.Error(err))
+			}
+			time.Sleep(snapshotInterval)
+		}
+	}()
+}
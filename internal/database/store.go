@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+)
+
+// RouterStore holds the router inventory
+type RouterStore interface {
+	GetAllRouters() ([]models.Router, error)
+	SaveRouter(r models.Router) error
+}
+
+// UserStore holds the pppoe_users mirror of router secrets
+type UserStore interface {
+	UpsertUser(username string, routerID int, profile string, remoteAddress string, isEnabled bool) error
+	SyncUsers(routerID int, secrets []models.PPPoESecret, policy string) (models.SyncSummary, error)
+	GetUsersByRouter(routerID int) (map[string]DBUser, error)
+	GetUser(routerID int, username string) (*DBUser, error)
+	FindUserRouterIDs(username string) ([]int, error)
+	FindUsers(username string) ([]DBUser, error)
+	FindUsersByAddress(ip string) ([]DBUser, error)
+	FindUsersByCallerID(mac string) ([]DBUser, error)
+	GetStaticAddresses() (map[string][]DBUser, error)
+}
+
+// SubscriberStore holds engine-owned state about subscribers
+type SubscriberStore interface {
+	SaveIsolation(iso models.Isolation) error
+	DeleteIsolation(username string, routerID int, list string) error
+	GetIsolationsByRouter(routerID int) ([]models.Isolation, error)
+
+	SaveSuspension(s models.Suspension) error
+	DeleteSuspension(username string, routerID int) error
+	SetSuspensionPreviousProfile(username string, routerID int, profile string) error
+	GetSuspension(username string, routerID int) (*models.Suspension, error)
+	GetSuspensionsByRouter(routerID int) (map[string]models.Suspension, error)
+}
+
+// HistoryStore holds session and usage history
+type HistoryStore interface {
+	OpenSession(s models.Session) error
+	CloseSession(routerID int, username, address string, endedAt time.Time) error
+	CloseSessions(sessions []models.Session, endedAt time.Time)
+	GetOpenSessions(routerID int) ([]models.Session, error)
+	GetUserSessions(username string, routerID int, from, to time.Time, limit int) ([]models.Session, error)
+
+	AddUsage(routerID int, hour time.Time, deltas []models.ByteCounters) error
+	GetHourlyUsage(username string, routerID int, from, to time.Time) ([]models.UsagePoint, error)
+	GetUsageTotals(since time.Time) (map[int]map[string]int64, error)
+}
+
+// FUPStore holds quota rules, who is limited and the transition log
+type FUPStore interface {
+	GetFUPRules() ([]models.FUPRule, error)
+	SaveFUPRule(r models.FUPRule) error
+	DeleteFUPRule(id int) error
+	GetFUPStates() ([]models.FUPState, error)
+	SaveFUPState(s models.FUPState) error
+	DeleteFUPState(username string, routerID int) error
+	AddFUPEvent(e models.FUPEvent) error
+	GetFUPEvents(username string, limit int) ([]models.FUPEvent, error)
+}
+
+// JobStore holds scheduled and async jobs
+type JobStore interface {
+	CreateScheduledJob(j models.ScheduledJob) (int64, error)
+	UpdateScheduledJob(j models.ScheduledJob) (bool, error)
+	CancelScheduledJob(id int64) (bool, error)
+	GetScheduledJob(id int64) (*models.ScheduledJob, error)
+	GetScheduledJobs(status, username string) ([]models.ScheduledJob, error)
+	GetDueJobs(now time.Time) ([]models.ScheduledJob, error)
+
+	SaveJob(j models.Job) error
+	GetJob(id string) (*models.Job, error)
+	GetJobs(status string, limit int) ([]models.Job, error)
+	FailInterruptedJobs(now time.Time) (int64, error)
+}
+
+// Store is everything the engine persists. New tables get their methods here
+// (and a migration for every dialect).
+type Store interface {
+	RouterStore
+	UserStore
+	SubscriberStore
+	HistoryStore
+	FUPStore
+	JobStore
+
+	MigrateUp(steps int) (int, error)
+	MigrateDown(steps int) (int, error)
+	MigrationStatus() ([]MigrationState, error)
+	Ping() error
+	Close() error
+}
+
+// SQLStore implements Store on database/sql. The dialect covers the few
+// statements MySQL and SQLite spell differently (upserts, locking).
+type SQLStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+type dialect interface {
+	name() string
+	// onConflict starts the update clause of an upsert on the given unique key columns
+	onConflict(keys string) string
+	// inserted refers to the value an upsert tried to insert into col
+	inserted(col string) string
+	// lock serializes migrations; unlock must be called on the same conn with the
+	// run's error and returns the run's outcome
+	lock(conn *sql.Conn) (unlock func(err error) error, err error)
+	// transactionalDDL reports whether a failed migration run is rolled back as a whole
+	transactionalDDL() bool
+	// syncLock serializes syncs of one router across processes; unlock must be called on the same conn
+	syncLock(conn *sql.Conn, routerID int) (unlock func(), err error)
+	// skippable reports migration errors that mean the change is already there
+	skippable(err error) bool
+}
+
+const pingTimeout = 5 * time.Second
+
+// Supported drivers
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// Open connects to a MySQL or SQLite database. The connection is not checked; call Ping.
+func Open(driver, dsn string) (*SQLStore, error) {
+	switch driver {
+	case DriverMySQL:
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(time.Hour)
+		return &SQLStore{db: db, dialect: mysqlDialect{}}, nil
+	case DriverSQLite:
+		db, err := sql.Open("sqlite", sqliteDSN(dsn))
+		if err != nil {
+			return nil, err
+		}
+		// SQLite allows one writer; a single connection also keeps ":memory:" databases shared
+		db.SetMaxOpenConns(1)
+		return &SQLStore{db: db, dialect: sqliteDialect{}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q (use %s or %s)", driver, DriverMySQL, DriverSQLite)
+	}
+}
+
+// DB exposes the underlying handle, e.g. for ad-hoc queries in tools
+func (st *SQLStore) DB() *sql.DB { return st.db }
+
+// Driver is the dialect name, "mysql" or "sqlite"
+func (st *SQLStore) Driver() string { return st.dialect.name() }
+
+// Ping checks the connection, giving up after pingTimeout
+func (st *SQLStore) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return st.db.PingContext(ctx)
+}
+
+func (st *SQLStore) Close() error { return st.db.Close() }
+
+// upsert ends an INSERT so that a row clashing on keys has cols overwritten with the inserted values
+func (st *SQLStore) upsert(keys string, cols ...string) string {
+	set := make([]string, len(cols))
+	for i, col := range cols {
+		set[i] = col + " = " + st.dialect.inserted(col)
+	}
+	return st.dialect.onConflict(keys) + " " + strings.Join(set, ", ")
+}
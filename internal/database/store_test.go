@@ -0,0 +1,239 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a migrated in-memory SQLite store
+func newTestStore(t *testing.T) *SQLStore {
+	t.Helper()
+	logger.Init()
+
+	st, err := Open(DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	_, err = st.MigrateUp(0)
+	require.NoError(t, err)
+	return st
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	st := newTestStore(t)
+
+	states, err := st.MigrationStatus()
+	require.NoError(t, err)
+	for _, s := range states {
+		assert.NotNil(t, s.AppliedAt, s.Name)
+	}
+
+	n, err := st.MigrateDown(len(states))
+	require.NoError(t, err)
+	assert.Equal(t, len(states), n)
+
+	n, err = st.MigrateUp(0)
+	require.NoError(t, err)
+	assert.Equal(t, len(states), n)
+}
+
+func TestSQLiteFailedMigrationRollsBack(t *testing.T) {
+	st := newTestStore(t)
+	states, err := st.MigrationStatus()
+	require.NoError(t, err)
+	latest := states[len(states)-1]
+
+	// Make the last revert down to 0002 fail: its last column is already gone
+	_, err = st.db.Exec("ALTER TABLE pppoe_users DROP COLUMN last_caller_id")
+	require.NoError(t, err)
+
+	n, err := st.MigrateDown(len(states) - 2)
+	require.Error(t, err)
+	assert.Zero(t, n)
+
+	// The reverts before it were undone with it
+	states, err = st.MigrationStatus()
+	require.NoError(t, err)
+	assert.NotNil(t, states[len(states)-1].AppliedAt, latest.Name)
+	_, err = st.db.Exec("SELECT throttle_profile FROM fup_states")
+	assert.NoError(t, err, "0011 revert was rolled back")
+	_, err = st.db.Exec("SELECT progress FROM async_jobs")
+	assert.NoError(t, err, "0010 revert was rolled back")
+}
+
+func TestSQLiteSyncUsers(t *testing.T) {
+	st := newTestStore(t)
+	logged := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	secrets := []models.PPPoESecret{
+		{Name: "alice", Profile: "10M", SecretDetails: models.SecretDetails{Comment: "C-1", LastLoggedOut: &logged}},
+		{Name: "bob", Profile: "20M", Disabled: true},
+	}
+
+	summary, err := st.SyncUsers(1, secrets, models.SyncDeleteMark)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Created)
+
+	secrets[0].Profile = "50M"
+	summary, err = st.SyncUsers(1, secrets[:1], models.SyncDeleteMark)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Updated)
+	assert.Equal(t, 1, summary.Removed)
+
+	users, err := st.GetUsersByRouter(1)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "50M", users["alice"].Profile)
+	assert.Equal(t, "C-1", users["alice"].Comment)
+	require.NotNil(t, users["alice"].LastLoggedOut)
+	assert.True(t, logged.Equal(*users["alice"].LastLoggedOut))
+
+	// A marked row comes back when the secret reappears
+	summary, err = st.SyncUsers(1, secrets, models.SyncDeleteMark)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Updated)
+	assert.Equal(t, 1, summary.Unchanged)
+
+	summary, err = st.SyncUsers(1, secrets[:1], models.SyncDeleteRemove)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Removed)
+	u, err := st.GetUser(1, "bob")
+	require.NoError(t, err)
+	assert.Nil(t, u)
+}
+
+// Two stores on one file stand in for the server and sync-users
+func TestSQLiteSyncUsersAcrossProcesses(t *testing.T) {
+	logger.Init()
+	path := filepath.Join(t.TempDir(), "netengine.db")
+	stores := make([]*SQLStore, 2)
+	for i := range stores {
+		st, err := Open(DriverSQLite, path)
+		require.NoError(t, err)
+		t.Cleanup(func() { st.Close() })
+		stores[i] = st
+	}
+	_, err := stores[0].MigrateUp(0)
+	require.NoError(t, err)
+
+	secrets := make([]models.PPPoESecret, 200)
+	for i := range secrets {
+		secrets[i] = models.PPPoESecret{Name: fmt.Sprintf("user%03d", i), Profile: "10M"}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(st *SQLStore, profile string) {
+			defer wg.Done()
+			batch := make([]models.PPPoESecret, len(secrets))
+			for j, s := range secrets {
+				s.Profile = profile
+				batch[j] = s
+			}
+			_, err := st.SyncUsers(1, batch, models.SyncDeleteMark)
+			errs <- err
+		}(stores[i%2], fmt.Sprintf("%dM", i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	users, err := stores[1].GetUsersByRouter(1)
+	require.NoError(t, err)
+	assert.Len(t, users, len(secrets))
+}
+
+func TestSQLiteUpserts(t *testing.T) {
+	st := newTestStore(t)
+
+	router := models.Router{ID: 7, Name: "core", Host: "10.0.0.1", Port: 8728, Username: "api", Password: "a"}
+	require.NoError(t, st.SaveRouter(router))
+	router.Password = "b"
+	require.NoError(t, st.SaveRouter(router))
+	routers, err := st.GetAllRouters()
+	require.NoError(t, err)
+	assert.Equal(t, []models.Router{router}, routers)
+
+	rule := models.FUPRule{Profile: "10M", QuotaBytes: 100, Action: "throttle", ThrottleProfile: "1M", Enabled: true}
+	require.NoError(t, st.SaveFUPRule(rule))
+	rule.QuotaBytes = 200
+	require.NoError(t, st.SaveFUPRule(rule))
+	rules, err := st.GetFUPRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, int64(200), rules[0].QuotaBytes)
+
+	hour := time.Now().Truncate(time.Hour)
+	deltas := []models.ByteCounters{{Name: "alice", RX: 10, TX: 1}}
+	require.NoError(t, st.AddUsage(1, hour, deltas))
+	require.NoError(t, st.AddUsage(1, hour, deltas))
+	totals, err := st.GetUsageTotals(hour.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(22), totals[1]["alice"])
+
+	job := models.Job{ID: "abc", Type: "sync", RouterID: 1, Status: models.JobQueued, CreatedAt: time.Now()}
+	require.NoError(t, st.SaveJob(job))
+	job.Status, job.Result = models.JobSucceeded, map[string]interface{}{"created": 3.0}
+	require.NoError(t, st.SaveJob(job))
+	got, err := st.GetJob("abc")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobSucceeded, got.Status)
+	assert.Equal(t, job.Result, got.Result)
+}
+
+func TestSQLiteDueJobs(t *testing.T) {
+	st := newTestStore(t)
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	for _, next := range []*time.Time{&past, &future} {
+		_, err := st.CreateScheduledJob(models.ScheduledJob{
+			Operation: models.Operation{Action: models.OpKick, User: "alice"},
+			NextRunAt: next, Status: models.SchedulePending, CreatedAt: now,
+		})
+		require.NoError(t, err)
+	}
+
+	due, err := st.GetDueJobs(now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.True(t, past.Equal(*due[0].NextRunAt))
+}
+
+func TestSQLiteFailInterruptedJobs(t *testing.T) {
+	st := newTestStore(t)
+	now := time.Now()
+	for id, status := range map[string]string{
+		"queued": models.JobQueued, "running": models.JobRunning, "done": models.JobSucceeded,
+	} {
+		require.NoError(t, st.SaveJob(models.Job{ID: id, Type: "sync", RouterID: 1, Status: status, CreatedAt: now}))
+	}
+
+	n, err := st.FailInterruptedJobs(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	for id, want := range map[string]string{
+		"queued": models.JobFailed, "running": models.JobFailed, "done": models.JobSucceeded,
+	} {
+		got, err := st.GetJob(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Status, id)
+		if want == models.JobFailed {
+			assert.Equal(t, "interrupted by restart", got.Error)
+			assert.NotNil(t, got.FinishedAt)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SaveSuspension stores a suspension before the router is changed
+func (st *SQLStore) SaveSuspension(s models.Suspension) error {
+	query := `
+		INSERT INTO suspensions (username, router_id, strategy, profile, previous_profile, previous_disabled, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := st.db.Exec(query, s.Username, s.RouterID, s.Strategy, s.Profile, s.PreviousProfile, s.PreviousDisabled, s.Comment, s.CreatedAt)
+	if err != nil {
+		logger.Error("Failed to save suspension", zap.String("user", s.Username), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteSuspension removes a suspension once the user has been resumed
+func (st *SQLStore) DeleteSuspension(username string, routerID int) error {
+	_, err := st.db.Exec("DELETE FROM suspensions WHERE username = ? AND router_id = ?", username, routerID)
+	if err != nil {
+		logger.Error("Failed to delete suspension", zap.String("user", username), zap.Error(err))
+	}
+	return err
+}
+
+// SetSuspensionPreviousProfile changes the profile a suspended user is resumed to
+func (st *SQLStore) SetSuspensionPreviousProfile(username string, routerID int, profile string) error {
+	_, err := st.db.Exec("UPDATE suspensions SET previous_profile = ? WHERE username = ? AND router_id = ?", profile, username, routerID)
+	if err != nil {
+		logger.Error("Failed to update suspension", zap.String("user", username), zap.Error(err))
+	}
+	return err
+}
+
+const selectSuspensions = "SELECT username, router_id, strategy, profile, previous_profile, previous_disabled, comment, created_at FROM suspensions"
+
+// GetSuspension fetches the active suspension of a user, or nil if there is none
+func (st *SQLStore) GetSuspension(username string, routerID int) (*models.Suspension, error) {
+	row := st.db.QueryRow(selectSuspensions+" WHERE username = ? AND router_id = ?", username, routerID)
+	s, err := scanSuspension(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to fetch suspension", zap.String("user", username), zap.Error(err))
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSuspensionsByRouter fetches all active suspensions of a router keyed by username
+func (st *SQLStore) GetSuspensionsByRouter(routerID int) (map[string]models.Suspension, error) {
+	rows, err := st.db.Query(selectSuspensions+" WHERE router_id = ?", routerID)
+	if err != nil {
+		logger.Error("Failed to fetch suspensions", zap.Int("router_id", routerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	suspensions := make(map[string]models.Suspension)
+	for rows.Next() {
+		s, err := scanSuspension(rows)
+		if err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		suspensions[s.Username] = *s
+	}
+
+	return suspensions, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSuspension(row scanner) (*models.Suspension, error) {
+	var s models.Suspension
+	var profile, comment sql.NullString
+	if err := row.Scan(&s.Username, &s.RouterID, &s.Strategy, &profile, &s.PreviousProfile, &s.PreviousDisabled, &comment, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	s.Profile = profile.String
+	s.Comment = comment.String
+	return &s, nil
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// syncBatchSize is the number of rows written per statement
+const syncBatchSize = 500
+
+// syncLockTimeout is how long a sync waits for another sync of the same router (seconds)
+const syncLockTimeout = 60
+
+type syncedRow struct {
+	DBUser
+	deleted bool
+}
+
+// matches reports whether the row already holds everything the sync writes
+func (r syncedRow) matches(s models.PPPoESecret) bool {
+	return !r.deleted && r.Profile == s.Profile && r.RemoteAddress == s.RemoteAddress && r.IsEnabled != s.Disabled &&
+		r.LocalAddress == s.LocalAddress && r.CallerID == s.CallerID && r.Service == s.Service &&
+		r.Comment == s.Comment && r.LastCallerID == s.LastCallerID && sameTime(r.LastLoggedOut, s.LastLoggedOut)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// SyncUsers makes pppoe_users match a router's secrets in a single
+// transaction, writing changed rows with batched multi-row upserts. Rows
+// whose secret is gone are marked deleted (deleted_at) or deleted, per
+// policy. An empty secret list never removes anything, since it more likely
+// means the router answered badly than that every secret is gone. Syncs of
+// the same router run one at a time, also across processes (the server and
+// sync-users).
+func (st *SQLStore) SyncUsers(routerID int, secrets []models.PPPoESecret, policy string) (models.SyncSummary, error) {
+	summary := models.SyncSummary{RouterID: routerID, Total: len(secrets), Policy: policy, StartedAt: time.Now()}
+
+	ctx := context.Background()
+	conn, err := st.db.Conn(ctx)
+	if err != nil {
+		return summary, err
+	}
+	defer conn.Close()
+
+	unlock, err := st.dialect.syncLock(conn, routerID)
+	if err != nil {
+		logger.Error("Failed to lock router for sync", zap.Int("router_id", routerID), zap.Error(err))
+		return summary, err
+	}
+	defer unlock()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to begin sync transaction", zap.Int("router_id", routerID), zap.Error(err))
+		return summary, err
+	}
+	defer tx.Rollback()
+
+	existing, err := loadSyncedRows(tx, routerID)
+	if err != nil {
+		return summary, err
+	}
+
+	changed := make([]models.PPPoESecret, 0)
+	present := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		present[s.Name] = true
+
+		row, ok := existing[s.Name]
+		switch {
+		case !ok:
+			summary.Created++
+		case !row.matches(s):
+			summary.Updated++
+		default:
+			summary.Unchanged++
+			continue
+		}
+		changed = append(changed, s)
+	}
+
+	for start := 0; start < len(changed); start += syncBatchSize {
+		if err := st.upsertBatch(tx, routerID, changed[start:min(start+syncBatchSize, len(changed))]); err != nil {
+			logger.Error("Failed to upsert users", zap.Int("router_id", routerID), zap.Error(err))
+			return summary, err
+		}
+	}
+
+	if len(secrets) > 0 {
+		gone := make([]string, 0)
+		for name, row := range existing {
+			if !present[name] && !row.deleted {
+				gone = append(gone, name)
+			}
+		}
+		for start := 0; start < len(gone); start += syncBatchSize {
+			if err := removeBatch(tx, routerID, gone[start:min(start+syncBatchSize, len(gone))], policy, summary.StartedAt); err != nil {
+				logger.Error("Failed to remove users", zap.Int("router_id", routerID), zap.Error(err))
+				return summary, err
+			}
+		}
+		summary.Removed = len(gone)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Failed to commit sync", zap.Int("router_id", routerID), zap.Error(err))
+		return summary, err
+	}
+
+	summary.FinishedAt = time.Now()
+	summary.DurationMs = summary.FinishedAt.Sub(summary.StartedAt).Milliseconds()
+	return summary, nil
+}
+
+func (st *SQLStore) upsertBatch(tx *sql.Tx, routerID int, secrets []models.PPPoESecret) error {
+	query := `
+		INSERT INTO pppoe_users (username, router_id, profile, remote_address, is_enabled,
+			local_address, caller_id, service, comment, last_logged_out, last_caller_id)
+		VALUES ` + placeholders(len(secrets), "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)") + `
+	` + st.upsert("username, router_id", "profile", "remote_address", "is_enabled", "local_address", "caller_id",
+		"service", "comment", "last_logged_out", "last_caller_id") + ", deleted_at = NULL, updated_at = CURRENT_TIMESTAMP"
+	args := make([]interface{}, 0, len(secrets)*11)
+	for _, s := range secrets {
+		args = append(args, s.Name, routerID, s.Profile, s.RemoteAddress, !s.Disabled,
+			s.LocalAddress, s.CallerID, s.Service, s.Comment, s.LastLoggedOut, s.LastCallerID)
+	}
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+func removeBatch(tx *sql.Tx, routerID int, usernames []string, policy string, now time.Time) error {
+	args := make([]interface{}, 0, len(usernames)+2)
+	var query string
+	if policy == models.SyncDeleteRemove {
+		query = "DELETE FROM pppoe_users WHERE router_id = ? AND username IN (" + placeholders(len(usernames), "?") + ")"
+	} else {
+		query = "UPDATE pppoe_users SET deleted_at = ? WHERE router_id = ? AND username IN (" + placeholders(len(usernames), "?") + ")"
+		args = append(args, now)
+	}
+	args = append(args, routerID)
+	for _, name := range usernames {
+		args = append(args, name)
+	}
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// placeholders repeats group n times, comma separated
+func placeholders(n int, group string) string {
+	return strings.TrimSuffix(strings.Repeat(group+", ", n), ", ")
+}
+
+func loadSyncedRows(tx *sql.Tx, routerID int) (map[string]syncedRow, error) {
+	rows, err := tx.Query("SELECT "+userColumns+", deleted_at FROM pppoe_users WHERE router_id = ?", routerID)
+	if err != nil {
+		logger.Error("Failed to load users for sync", zap.Int("router_id", routerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]syncedRow)
+	for rows.Next() {
+		var deletedAt sql.NullTime
+		u, err := scanUser(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		existing[u.Username] = syncedRow{DBUser: u, deleted: deletedAt.Valid}
+	}
+	return existing, rows.Err()
+}
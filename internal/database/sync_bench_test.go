@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+)
+
+// The sync benchmarks run on a temporary SQLite file, or on a scratch MySQL database:
+//
+//	BENCH_DB_DSN="user@tcp(127.0.0.1:3306)/netengine_bench?parseTime=true" go test -bench Sync ./internal/database
+//
+// They use a router ID no real router has and delete its rows afterwards.
+const benchRouterID = 999999
+
+func openBenchDB(b *testing.B) *SQLStore {
+	logger.Init()
+
+	driver, dsn := DriverMySQL, os.Getenv("BENCH_DB_DSN")
+	if dsn == "" {
+		driver, dsn = DriverSQLite, filepath.Join(b.TempDir(), "bench.db")
+	}
+	st, err := Open(driver, dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := st.Ping(); err != nil {
+		b.Skipf("benchmark database unreachable: %v", err)
+	}
+
+	if _, err := st.MigrateUp(0); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Cleanup(func() {
+		st.db.Exec("DELETE FROM pppoe_users WHERE router_id = ?", benchRouterID)
+		st.Close()
+	})
+	return st
+}
+
+func benchSecrets(n int, profile string) []models.PPPoESecret {
+	secrets := make([]models.PPPoESecret, n)
+	for i := range secrets {
+		secrets[i] = models.PPPoESecret{Name: fmt.Sprintf("bench%05d", i), Profile: profile}
+	}
+	return secrets
+}
+
+// BenchmarkSyncUsers alternates profiles so that every iteration rewrites every row
+func BenchmarkSyncUsers(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			st := openBenchDB(b)
+			profiles := [2][]models.PPPoESecret{benchSecrets(n, "10M"), benchSecrets(n, "20M")}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := st.SyncUsers(benchRouterID, profiles[i%2], models.SyncDeleteMark); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkUpsertUserPerRow is the previous one-statement-per-secret sync, for comparison
+func BenchmarkUpsertUserPerRow(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			st := openBenchDB(b)
+			profiles := [2][]models.PPPoESecret{benchSecrets(n, "10M"), benchSecrets(n, "20M")}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, s := range profiles[i%2] {
+					if err := st.UpsertUser(s.Name, benchRouterID, s.Profile, s.RemoteAddress, !s.Disabled); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// AddUsage adds byte deltas to the hourly usage rows of a router
+func (st *SQLStore) AddUsage(routerID int, hour time.Time, deltas []models.ByteCounters) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		logger.Error("Failed to start usage transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO pppoe_usage (username, router_id, period_start, rx_bytes, tx_bytes)
+		VALUES (?, ?, ?, ?, ?)
+	` + st.dialect.onConflict("username, router_id, period_start") + `
+			rx_bytes = rx_bytes + ` + st.dialect.inserted("rx_bytes") + `,
+			tx_bytes = tx_bytes + ` + st.dialect.inserted("tx_bytes"))
+	if err != nil {
+		logger.Error("Failed to prepare usage upsert", zap.Error(err))
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range deltas {
+		if _, err := stmt.Exec(d.Name, routerID, hour, d.RX, d.TX); err != nil {
+			logger.Error("Failed to add usage", zap.String("user", d.Name), zap.Error(err))
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetHourlyUsage fetches a user's hourly usage rows in [from, to), oldest first.
+// routerID 0 means all routers (rows of the same hour are summed).
+func (st *SQLStore) GetHourlyUsage(username string, routerID int, from, to time.Time) ([]models.UsagePoint, error) {
+	query := "SELECT period_start, rx_bytes, tx_bytes FROM pppoe_usage WHERE username = ? AND period_start >= ? AND period_start < ?"
+	args := []interface{}{username, from, to}
+	if routerID != 0 {
+		query += " AND router_id = ?"
+		args = append(args, routerID)
+	}
+	query += " ORDER BY period_start"
+
+	rows, err := st.db.Query(query, args...)
+	if err != nil {
+		logger.Error("Failed to fetch usage", zap.String("user", username), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]models.UsagePoint, 0)
+	for rows.Next() {
+		var p models.UsagePoint
+		if err := rows.Scan(&p.Period, &p.RX, &p.TX); err != nil {
+			logger.Error("Scan error", zap.Error(err))
+			continue
+		}
+		if n := len(points); n > 0 && points[n-1].Period.Equal(p.Period) {
+			points[n-1].RX += p.RX
+			points[n-1].TX += p.TX
+			continue
+		}
+		points = append(points, p)
+	}
+
+	return points, nil
+}
@@ -0,0 +1,174 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPort is used for entries without a port
+const DefaultPort = 8728
+
+// File is the layout of routers.yaml / routers.json
+type File struct {
+	Routers []Entry `yaml:"routers" json:"routers"`
+}
+
+// Entry is one router. Every string may reference environment variables as ${NAME}.
+type Entry struct {
+	ID       int    `yaml:"id" json:"id"`
+	Name     string `yaml:"name" json:"name"`
+	Host     string `yaml:"host" json:"host"`
+	Port     int    `yaml:"port" json:"port"`
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// Load reads a router file. ".json" files are parsed as JSON, anything else as YAML.
+func Load(path string) ([]models.Router, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, strings.EqualFold(filepath.Ext(path), ".json"))
+}
+
+// Parse decodes, interpolates and validates a router file
+func Parse(data []byte, isJSON bool) ([]models.Router, error) {
+	var f File
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+	}
+
+	routers := make([]models.Router, 0, len(f.Routers))
+	seen := make(map[int]bool, len(f.Routers))
+	for i, e := range f.Routers {
+		r := models.Router{ID: e.ID, Port: e.Port}
+		for _, field := range []struct {
+			dst   *string
+			value string
+			name  string
+		}{
+			{&r.Name, e.Name, "name"},
+			{&r.Host, e.Host, "host"},
+			{&r.Username, e.Username, "username"},
+			{&r.Password, e.Password, "password"},
+		} {
+			value, err := Interpolate(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("router %d (%s) %s: %w", i+1, e.Name, field.name, err)
+			}
+			*field.dst = value
+		}
+
+		if r.Port == 0 {
+			r.Port = DefaultPort
+		}
+		switch {
+		case r.ID <= 0:
+			return nil, fmt.Errorf("router %d (%s): id must be a positive number", i+1, r.Name)
+		case seen[r.ID]:
+			return nil, fmt.Errorf("router %d (%s): duplicate id %d", i+1, r.Name, r.ID)
+		case r.Name == "" || r.Host == "" || r.Username == "":
+			return nil, fmt.Errorf("router %d: name, host and username are required", i+1)
+		case r.Port < 1 || r.Port > 65535:
+			return nil, fmt.Errorf("router %d (%s): invalid port %d", i+1, r.Name, r.Port)
+		}
+		seen[r.ID] = true
+		routers = append(routers, r)
+	}
+	return routers, nil
+}
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate replaces ${NAME} with the environment variable NAME. Unset
+// variables are an error, so a missing secret never becomes an empty password.
+// A lone "$" (common in passwords) is kept as is.
+func Interpolate(s string) (string, error) {
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// Merge combines database and file routers by ID; file entries win
+func Merge(db, file []models.Router) []models.Router {
+	byID := make(map[int]models.Router, len(db)+len(file))
+	for _, r := range db {
+		byID[r.ID] = r
+	}
+	for _, r := range file {
+		if existing, ok := byID[r.ID]; ok && existing != r {
+			logger.Info("Router file overrides database entry", zap.Int("id", r.ID), zap.String("name", r.Name))
+		}
+		byID[r.ID] = r
+	}
+
+	routers := make([]models.Router, 0, len(byID))
+	for _, r := range byID {
+		routers = append(routers, r)
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i].ID < routers[j].ID })
+	return routers
+}
+
+// Watch polls a file and calls onChange after its contents change.
+// Editors often write in several steps, so a change is reported once the file is stable for one interval.
+func Watch(path string, interval time.Duration, onChange func()) {
+	go func() {
+		last := fileVersion(path)
+		pending := false
+		for {
+			time.Sleep(interval)
+			v := fileVersion(path)
+			switch {
+			case v != last:
+				last, pending = v, true
+			case pending:
+				pending = false
+				onChange()
+			}
+		}
+	}()
+}
+
+// fileVersion identifies a file's contents cheaply (size and modification time)
+func fileVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
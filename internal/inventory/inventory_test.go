@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"testing"
+
+	"skynet-net-engine-api/internal/models"
+	"skynet-net-engine-api/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseYAML(t *testing.T) {
+	t.Setenv("ROUTER_PASS", "s3cret")
+	routers, err := Parse([]byte(`
+routers:
+  - id: 1
+    name: core
+    host: 10.0.0.1
+    username: api
+    password: "${ROUTER_PASS}$x"
+`), false)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Router{
+		{ID: 1, Name: "core", Host: "10.0.0.1", Port: DefaultPort, Username: "api", Password: "s3cret$x"},
+	}, routers)
+}
+
+func TestParseJSON(t *testing.T) {
+	routers, err := Parse([]byte(`{"routers":[{"id":2,"name":"edge","host":"edge.example","port":8729,"username":"api","password":"p"}]}`), true)
+	require.NoError(t, err)
+	require.Len(t, routers, 1)
+	assert.Equal(t, 8729, routers[0].Port)
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing variable": "routers: [{id: 1, name: a, host: h, username: u, password: '${ROUTER_PASS_UNSET}'}]",
+		"unknown field":    "routers: [{id: 1, name: a, host: h, username: u, pass: p}]",
+		"no id":            "routers: [{name: a, host: h, username: u}]",
+		"duplicate id":     "routers: [{id: 1, name: a, host: h, username: u}, {id: 1, name: b, host: h2, username: u}]",
+		"no host":          "routers: [{id: 1, name: a, username: u}]",
+		"bad port":         "routers: [{id: 1, name: a, host: h, port: 70000, username: u}]",
+	} {
+		_, err := Parse([]byte(data), false)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	logger.Init()
+	db := []models.Router{
+		{ID: 2, Name: "edge", Password: "old"},
+		{ID: 1, Name: "core"},
+	}
+	file := []models.Router{
+		{ID: 2, Name: "edge", Password: "new"},
+		{ID: 3, Name: "lab"},
+	}
+
+	merged := Merge(db, file)
+	require.Len(t, merged, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{merged[0].ID, merged[1].ID, merged[2].ID})
+	assert.Equal(t, "new", merged[1].Password)
+}
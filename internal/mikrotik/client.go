@@ -1,9 +1,12 @@
 package mikrotik
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"time"
 	"skynet-net-engine-api/pkg/logger"
 	"skynet-net-engine-api/internal/models"
 	
@@ -11,6 +14,8 @@ import (
 	"github.com/go-routeros/routeros"
 )
 
+var errUserNotFound = errors.New("user not found")
+
 type Client struct {
 	Conn *routeros.Client
 	Router models.Router
@@ -75,7 +80,7 @@ func (c *Client) SetSecretProfile(user, newProfile string) error {
 		return err
 	}
 	if len(res.Re) == 0 {
-		return fmt.Errorf("user not found")
+		return errUserNotFound
 	}
 	id := res.Re[0].Map[".id"]
 
@@ -84,9 +89,81 @@ func (c *Client) SetSecretProfile(user, newProfile string) error {
 	return err
 }
 
-// GetAllSecrets fetches all PPPoE secrets from the router
+// GetSecret fetches a single secret by name, or nil if it does not exist
+func (c *Client) GetSecret(user string) (*models.PPPoESecret, error) {
+	res, err := c.Conn.Run("/ppp/secret/print", "?name="+user, "=.proplist=name,profile,remote-address,disabled")
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Re) == 0 {
+		return nil, nil
+	}
+
+	m := res.Re[0].Map
+	return &models.PPPoESecret{
+		Name:          m["name"],
+		Profile:       m["profile"],
+		RemoteAddress: m["remote-address"],
+		Disabled:      m["disabled"] == "true",
+	}, nil
+}
+
+func (c *Client) SetSecretDisabled(user string, disabled bool) error {
+	res, err := c.Conn.Run("/ppp/secret/print", "?name="+user, "=.proplist=.id")
+	if err != nil {
+		return err
+	}
+	if len(res.Re) == 0 {
+		return errUserNotFound
+	}
+	id := res.Re[0].Map[".id"]
+
+	value := "no"
+	if disabled {
+		value = "yes"
+	}
+	_, err = c.Conn.Run("/ppp/secret/set", "=.id="+id, "=disabled="+value)
+	return err
+}
+
+// SetSecretFields sets RouterOS properties (e.g. "profile", "disabled") on an existing secret
+func (c *Client) SetSecretFields(user string, fields map[string]string) error {
+	res, err := c.Conn.Run("/ppp/secret/print", "?name="+user, "=.proplist=.id")
+	if err != nil {
+		return err
+	}
+	if len(res.Re) == 0 {
+		return errUserNotFound
+	}
+
+	args := changedFields(map[string]string{}, fields)
+	if len(args) == 0 {
+		return nil
+	}
+	_, err = c.Conn.RunArgs(append([]string{"/ppp/secret/set", "=.id=" + res.Re[0].Map[".id"]}, args...))
+	return err
+}
+
+// KickUser drops all active PPP sessions of a user. Not being online is not an error.
+func (c *Client) KickUser(user string) (int, error) {
+	res, err := c.Conn.Run("/ppp/active/print", "?name="+user, "=.proplist=.id")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, re := range res.Re {
+		if _, err := c.Conn.Run("/ppp/active/remove", "=.id="+re.Map[".id"]); err != nil {
+			return 0, err
+		}
+	}
+	return len(res.Re), nil
+}
+
+// GetAllSecrets fetches all PPPoE secrets from the router. last-logged-out
+// and last-caller-id only exist on RouterOS v7 and stay empty on v6.
 func (c *Client) GetAllSecrets() ([]models.PPPoESecret, error) {
-	res, err := c.Conn.Run("/ppp/secret/print", "=.proplist=name,profile,remote-address,disabled")
+	res, err := c.Conn.Run("/ppp/secret/print",
+		"=.proplist=name,profile,remote-address,disabled,local-address,caller-id,service,comment,last-logged-out,last-caller-id")
 	if err != nil {
 		return nil, err
 	}
@@ -99,12 +176,36 @@ func (c *Client) GetAllSecrets() ([]models.PPPoESecret, error) {
 			Profile:       re.Map["profile"],
 			RemoteAddress: re.Map["remote-address"],
 			Disabled:      disabled,
+			SecretDetails: models.SecretDetails{
+				LocalAddress:  re.Map["local-address"],
+				CallerID:      re.Map["caller-id"],
+				Service:       re.Map["service"],
+				Comment:       re.Map["comment"],
+				LastLoggedOut: ParseTimestamp(re.Map["last-logged-out"]),
+				LastCallerID:  re.Map["last-caller-id"],
+			},
 		})
 	}
 
 	return secrets, nil
 }
 
+// ParseTimestamp parses RouterOS date-times in the old "jan/02/2006 15:04:05"
+// and the v7.10+ "2006-01-02 15:04:05" formats, in server local time.
+// Empty values and "never" yield nil.
+func ParseTimestamp(s string) *time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", "Jan/02/2006 15:04:05"} {
+		value := s
+		if layout[0] == 'J' && len(s) > 0 {
+			value = strings.ToUpper(s[:1]) + s[1:] // "jan" -> "Jan"
+		}
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
 func (c *Client) AddAddressList(ip, list, comment string) error {
 	_, err := c.Conn.Run(
 		"/ip/firewall/address-list/add",
@@ -130,6 +231,28 @@ func (c *Client) RemoveAddressList(ip, list string) error {
 	return nil
 }
 
+// GetAddressListEntries fetches the enabled entries of the given address lists
+func (c *Client) GetAddressListEntries(lists []string) ([]models.AddressListEntry, error) {
+	entries := make([]models.AddressListEntry, 0)
+	for _, list := range lists {
+		res, err := c.Conn.Run("/ip/firewall/address-list/print", "?list="+list, "=.proplist=list,address,comment,disabled")
+		if err != nil {
+			return nil, err
+		}
+		for _, re := range res.Re {
+			if re.Map["disabled"] == "true" {
+				continue
+			}
+			entries = append(entries, models.AddressListEntry{
+				List:    re.Map["list"],
+				Address: re.Map["address"],
+				Comment: re.Map["comment"],
+			})
+		}
+	}
+	return entries, nil
+}
+
 func (c *Client) GetActiveUsers() ([]models.ActiveUser, error) {
 	// Optimizing query to prevent buffer overflow on large responses
 	res, err := c.Conn.Run("/ppp/active/print", "=.proplist=name,address,caller-id,uptime")
@@ -234,8 +357,146 @@ func (c *Client) GetQueueTraffic(target string) (*models.TrafficStats, error) {
 	}, nil
 }
 
+// GetIPPoolUsage reads /ip/pool and counts /ip/pool/used entries per pool
+func (c *Client) GetIPPoolUsage() ([]models.IPPoolUsage, error) {
+	res, err := c.Conn.Run("/ip/pool/print", "=.proplist=name,ranges")
+	if err != nil {
+		return nil, err
+	}
+	used, err := c.Conn.Run("/ip/pool/used/print", "=.proplist=pool")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, re := range used.Re {
+		counts[re.Map["pool"]]++
+	}
+
+	pools := make([]models.IPPoolUsage, 0, len(res.Re))
+	for _, re := range res.Re {
+		p := models.IPPoolUsage{
+			RouterID: c.Router.ID,
+			Name:     re.Map["name"],
+			Ranges:   re.Map["ranges"],
+			Used:     counts[re.Map["name"]],
+		}
+		p.Size = PoolSize(p.Ranges)
+		if p.Size > 0 {
+			p.Utilization = float64(p.Used) * 100 / float64(p.Size)
+		}
+		pools = append(pools, p)
+	}
+	return pools, nil
+}
+
+// PoolSize counts the IPv4 addresses in a pool's ranges, e.g.
+// "10.0.0.2-10.0.0.254,10.0.1.0/24". Unparseable parts count as zero.
+func PoolSize(ranges string) int64 {
+	var total int64
+	for _, part := range strings.Split(ranges, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.Contains(part, "/"):
+			_, network, err := net.ParseCIDR(part)
+			if err != nil {
+				continue
+			}
+			ones, bits := network.Mask.Size()
+			if bits == 32 {
+				total += int64(1) << uint(bits-ones)
+			}
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			from, to := ipv4ToInt(bounds[0]), ipv4ToInt(bounds[1])
+			if from >= 0 && to >= from {
+				total += to - from + 1
+			}
+		default:
+			if ipv4ToInt(part) >= 0 {
+				total++
+			}
+		}
+	}
+	return total
+}
+
+func ipv4ToInt(s string) int64 {
+	ip := net.ParseIP(strings.TrimSpace(s)).To4()
+	if ip == nil {
+		return -1
+	}
+	return int64(ip[0])<<24 | int64(ip[1])<<16 | int64(ip[2])<<8 | int64(ip[3])
+}
+
+// GetQueueCounters reads the cumulative byte counters of all simple queues,
+// keyed by username. Dynamic PPPoE queues ("<pppoe-USER>") are mapped to USER.
+func (c *Client) GetQueueCounters() (map[string]models.ByteCounters, error) {
+	res, err := c.Conn.Run("/queue/simple/print", "=.proplist=name,bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]models.ByteCounters, len(res.Re))
+	for _, re := range res.Re {
+		name := re.Map["name"]
+		if strings.HasPrefix(name, "<pppoe-") && strings.HasSuffix(name, ">") {
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "<pppoe-"), ">")
+		}
+
+		// Bytes come like "rx/tx", the same layout as rate
+		var rx, tx int64
+		fmt.Sscanf(re.Map["bytes"], "%d/%d", &rx, &tx)
+		counters[name] = models.ByteCounters{Name: name, RX: rx, TX: tx}
+	}
+	return counters, nil
+}
+
 func (c *Client) RunBackup(name string) error {
 	_, err := c.Conn.Run("/system/backup/save", "=name="+name)
 	return err
 }
 
+
+// ParseDuration converts a RouterOS duration such as "1w2d3h4m5s" (or the
+// v7 "3d04:05:06" form) into a time.Duration. Unknown input yields 0.
+func ParseDuration(s string) time.Duration {
+	var total time.Duration
+
+	// v7 style: optional "<n>w<n>d" prefix followed by hh:mm:ss
+	if i := strings.LastIndexAny(s, "wd"); strings.Contains(s, ":") {
+		clock := s[i+1:]
+		var h, m, sec int
+		if _, err := fmt.Sscanf(clock, "%d:%d:%d", &h, &m, &sec); err != nil {
+			return 0
+		}
+		total = time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+		s = s[:i+1]
+	}
+
+	units := map[byte]time.Duration{
+		'w': 7 * 24 * time.Hour,
+		'd': 24 * time.Hour,
+		'h': time.Hour,
+		'm': time.Minute,
+		's': time.Second,
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+			n = n*10 + int(ch-'0')
+		case ch == 'm' && i+1 < len(s) && s[i+1] == 's':
+			total += time.Duration(n) * time.Millisecond
+			n = 0
+			i++
+		case units[ch] != 0:
+			total += time.Duration(n) * units[ch]
+			n = 0
+		default:
+			return 0
+		}
+	}
+	return total
+}
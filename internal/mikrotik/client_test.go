@@ -0,0 +1,38 @@
+package mikrotik
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	assert.Equal(t, 9*24*time.Hour+3*time.Hour+4*time.Minute+5*time.Second, ParseDuration("1w2d3h4m5s"))
+	assert.Equal(t, 3*24*time.Hour+4*time.Hour+5*time.Minute+6*time.Second, ParseDuration("3d04:05:06"))
+	assert.Equal(t, 90*time.Second, ParseDuration("00:01:30"))
+	assert.Equal(t, time.Duration(0), ParseDuration("garbage"))
+}
+
+func TestPoolSize(t *testing.T) {
+	assert.Equal(t, int64(253), PoolSize("10.0.0.2-10.0.0.254"))
+	assert.Equal(t, int64(256+253), PoolSize("10.0.1.0/24, 10.0.0.2-10.0.0.254"))
+	assert.Equal(t, int64(1), PoolSize("10.0.0.9"))
+	assert.Equal(t, int64(0), PoolSize("nonsense"))
+}
+
+func TestChangedFields(t *testing.T) {
+	current := map[string]string{".id": "*1", "profile": "10M", "remote-address": "10.0.0.5"}
+
+	assert.Empty(t, changedFields(current, map[string]string{"profile": "10M", "comment": ""}))
+	assert.Equal(t, []string{"=comment=CUST-1", "=profile=20M"},
+		changedFields(current, map[string]string{"profile": "20M", "remote-address": "10.0.0.5", "comment": "CUST-1"}))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 1, 2, 10, 4, 5, 0, time.Local)
+	assert.Equal(t, want, *ParseTimestamp("2024-01-02 10:04:05"))
+	assert.Equal(t, want, *ParseTimestamp("jan/02/2024 10:04:05"))
+	assert.Nil(t, ParseTimestamp("never"))
+	assert.Nil(t, ParseTimestamp(""))
+}
@@ -0,0 +1,149 @@
+package mikrotik
+
+import (
+	"sort"
+)
+
+// The Ensure* methods check the router's current state before changing it,
+// so repeating them is harmless. Each reports whether anything changed.
+
+// EnsureSecret creates the secret if it is missing and otherwise updates the
+// fields that differ. Empty fields are left as they are. The password is only
+// compared when the API user may read it (the "sensitive" policy).
+func (c *Client) EnsureSecret(user, password, profile, localIP, remoteIP, comment string) (bool, error) {
+	res, err := c.Conn.Run("/ppp/secret/print", "?name="+user, "=.proplist=.id,password,profile,local-address,remote-address,comment")
+	if err != nil {
+		return false, err
+	}
+	if len(res.Re) == 0 {
+		if err := c.AddSecret(user, password, profile, localIP, remoteIP, comment); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	current := res.Re[0].Map
+	if _, readable := current["password"]; !readable {
+		password = ""
+	}
+	args := changedFields(current, map[string]string{
+		"password":       password,
+		"profile":        profile,
+		"local-address":  localIP,
+		"remote-address": remoteIP,
+		"comment":        comment,
+	})
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	_, err = c.Conn.RunArgs(append([]string{"/ppp/secret/set", "=.id=" + current[".id"]}, args...))
+	return err == nil, err
+}
+
+// EnsureSecretProfile sets the profile of an existing secret unless it already has it
+func (c *Client) EnsureSecretProfile(user, profile string) (bool, error) {
+	secret, err := c.GetSecret(user)
+	if err != nil {
+		return false, err
+	}
+	if secret == nil {
+		return false, errUserNotFound
+	}
+	if secret.Profile == profile {
+		return false, nil
+	}
+	if err := c.SetSecretProfile(user, profile); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureSecretDisabled enables or disables a secret unless it already is
+func (c *Client) EnsureSecretDisabled(user string, disabled bool) (bool, error) {
+	secret, err := c.GetSecret(user)
+	if err != nil {
+		return false, err
+	}
+	if secret == nil {
+		return false, errUserNotFound
+	}
+	if secret.Disabled == disabled {
+		return false, nil
+	}
+	if err := c.SetSecretDisabled(user, disabled); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureSecretAbsent removes a secret if it exists
+func (c *Client) EnsureSecretAbsent(user string) (bool, error) {
+	res, err := c.Conn.Run("/ppp/secret/print", "?name="+user, "=.proplist=.id")
+	if err != nil {
+		return false, err
+	}
+	for _, re := range res.Re {
+		if _, err := c.Conn.Run("/ppp/secret/remove", "=.id="+re.Map[".id"]); err != nil {
+			return false, err
+		}
+	}
+	return len(res.Re) > 0, nil
+}
+
+// EnsureAddressList adds ip to list unless an entry exists; an existing
+// entry with a different comment gets the new comment.
+func (c *Client) EnsureAddressList(ip, list, comment string) (bool, error) {
+	res, err := c.Conn.Run("/ip/firewall/address-list/print", "?address="+ip, "?list="+list, "=.proplist=.id,comment")
+	if err != nil {
+		return false, err
+	}
+	if len(res.Re) == 0 {
+		if err := c.AddAddressList(ip, list, comment); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	current := res.Re[0].Map
+	if current["comment"] == comment {
+		return false, nil
+	}
+	_, err = c.Conn.Run("/ip/firewall/address-list/set", "=.id="+current[".id"], "=comment="+comment)
+	return err == nil, err
+}
+
+// EnsureAddressListAbsent removes every entry of ip from list
+func (c *Client) EnsureAddressListAbsent(ip, list string) (bool, error) {
+	res, err := c.Conn.Run("/ip/firewall/address-list/print", "?address="+ip, "?list="+list, "=.proplist=.id")
+	if err != nil {
+		return false, err
+	}
+	for _, re := range res.Re {
+		if _, err := c.Conn.Run("/ip/firewall/address-list/remove", "=.id="+re.Map[".id"]); err != nil {
+			return false, err
+		}
+	}
+	return len(res.Re) > 0, nil
+}
+
+// changedFields returns "=key=value" arguments for the desired fields that
+// differ from current. Empty desired values mean "leave as is"; RouterOS
+// omits empty properties, so a missing current value counts as empty.
+func changedFields(current, desired map[string]string) []string {
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, k := range keys {
+		want := desired[k]
+		if want == "" || current[k] == want {
+			continue
+		}
+		args = append(args, "="+k+"="+want)
+	}
+	return args
+}
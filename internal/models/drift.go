@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// SecretState is the part of a secret compared between the DB and a router
+type SecretState struct {
+	Profile       string `json:"profile"`
+	RemoteAddress string `json:"remote_address,omitempty"`
+	Enabled       bool   `json:"enabled"`
+}
+
+// SecretDrift is a secret that differs between the DB and a router
+type SecretDrift struct {
+	Username string       `json:"username"`
+	Fields   []string     `json:"fields,omitempty"` // Mismatched fields: profile, remote_address, enabled
+	Router   *SecretState `json:"router,omitempty"`
+	DB       *SecretState `json:"db,omitempty"`
+}
+
+// DriftReport compares a router's secrets with pppoe_users
+type DriftReport struct {
+	RouterID    int           `json:"router_id"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	InSync      int           `json:"in_sync"`
+	Managed     int           `json:"managed"` // Suspended or FUP-limited users left out
+	OnlyRouter  []SecretDrift `json:"only_router"`
+	OnlyDB      []SecretDrift `json:"only_db"`
+	Mismatched  []SecretDrift `json:"mismatched"`
+}
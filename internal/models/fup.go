@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// FUP actions
+const (
+	FUPThrottle = "throttle" // Move the secret to ThrottleProfile
+	FUPIsolate  = "isolate"  // Put the subscriber on AddressList
+)
+
+// FUPRule is a monthly quota for every subscriber on a profile
+type FUPRule struct {
+	ID              int    `json:"id"`
+	Profile         string `json:"profile" binding:"required"`
+	QuotaBytes      int64  `json:"quota_bytes" binding:"required,gt=0"`
+	Action          string `json:"action" binding:"required,oneof=throttle isolate"`
+	ThrottleProfile string `json:"throttle_profile,omitempty" binding:"required_if=Action throttle"`
+	AddressList     string `json:"address_list,omitempty" binding:"required_if=Action isolate"`
+	Enabled         bool   `json:"enabled"`
+}
+
+// FUPState is a subscriber currently over quota
+type FUPState struct {
+	Username        string    `json:"username"`
+	RouterID        int       `json:"router_id"`
+	RuleID          int       `json:"rule_id"`
+	Action          string    `json:"action"`
+	OriginalProfile string    `json:"original_profile"`
+	ThrottleProfile string    `json:"throttle_profile,omitempty"`
+	AddressList     string    `json:"address_list,omitempty"`
+	CycleStart      time.Time `json:"cycle_start"`
+	AppliedAt       time.Time `json:"applied_at"`
+}
+
+// FUPEvent is one recorded FUP transition
+type FUPEvent struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	RouterID   int       `json:"router_id"`
+	RuleID     int       `json:"rule_id"`
+	Event      string    `json:"event"` // "exceeded" or "restored"
+	Action     string    `json:"action"`
+	UsageBytes int64     `json:"usage_bytes"`
+	QuotaBytes int64     `json:"quota_bytes"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// IPPoolUsage is the utilization of a RouterOS /ip/pool
+type IPPoolUsage struct {
+	RouterID    int     `json:"router_id"`
+	Name        string  `json:"name"`
+	Ranges      string  `json:"ranges"`
+	Size        int64   `json:"size"`
+	Used        int64   `json:"used"`
+	Utilization float64 `json:"utilization"` // Percent of Size in use
+}
+
+// IPConflict is an address claimed by more than one subscriber
+type IPConflict struct {
+	Address  string            `json:"address"`
+	Kind     string            `json:"kind"` // "static", "session" or "static_in_use"
+	Sessions []ActiveUser      `json:"sessions,omitempty"`
+	Secrets  []IPConflictOwner `json:"secrets,omitempty"`
+}
+
+// IPConflictOwner is a secret whose static remote-address takes part in a conflict
+type IPConflictOwner struct {
+	Username string `json:"username"`
+	RouterID int    `json:"router_id"`
+}
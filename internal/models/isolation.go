@@ -0,0 +1,18 @@
+package models
+
+// Isolation is a username-based address-list entry. The engine keeps the
+// entry pointed at whatever IP the subscriber currently holds.
+type Isolation struct {
+	Username string `json:"username"`
+	RouterID int    `json:"router_id"`
+	List     string `json:"list"`
+	Comment  string `json:"comment,omitempty"`
+	Address  string `json:"address,omitempty"` // IP currently on the address list, empty while unresolved
+}
+
+// AddressListEntry is a firewall address-list entry as cached by the worker
+type AddressListEntry struct {
+	List    string `json:"list"`
+	Address string `json:"address"`
+	Comment string `json:"comment,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Async job states
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Job is a long-running command (sync, backup) executed in the background
+type Job struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	RouterID   int         `json:"router_id"`
+	Status     string      `json:"status"`
+	Progress   int         `json:"progress"` // Percent
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// Operation actions shared by the scheduler and the bulk API
+const (
+	OpCreateSecret  = "create_secret"
+	OpChangeProfile = "change_profile"
+	OpIsolate       = "isolate"
+	OpUnisolate     = "unisolate"
+	OpKick          = "kick"
+)
+
+// Operation is one subscriber change to run on the router the subscriber lives on
+type Operation struct {
+	Action   string `json:"action" binding:"required,oneof=create_secret change_profile isolate unisolate kick"`
+	User     string `json:"user" binding:"required"`
+	RouterID int    `json:"router_id,omitempty"` // Optional; resolved from the user when omitted
+	Profile  string `json:"profile,omitempty"`   // create_secret, change_profile
+	Password string `json:"password,omitempty"`  // create_secret
+	RemoteIP string `json:"remote_ip,omitempty"` // create_secret
+	LocalIP  string `json:"local_ip,omitempty"`  // create_secret
+	List     string `json:"list,omitempty"`      // isolate, unisolate; defaults to "ISOLATED"
+	Comment  string `json:"comment,omitempty"`
+}
+
+// OperationResult is the outcome of one item of a bulk request
+type OperationResult struct {
+	Index    int         `json:"index"`
+	Action   string      `json:"action"`
+	User     string      `json:"user"`
+	RouterID int         `json:"router_id,omitempty"`
+	Status   string      `json:"status"` // "ok", "error", "skipped" or "planned" (dry run)
+	Error    string      `json:"error,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
+}
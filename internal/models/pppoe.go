@@ -1,18 +1,35 @@
 package models
 
+import "time"
+
 // PPPoESecret represents a PPPoE account from MikroTik
 type PPPoESecret struct {
 	Name          string
 	Profile       string
 	RemoteAddress string
 	Disabled      bool
+	SecretDetails
+}
+
+// SecretDetails are the secret attributes synced for reference only
+type SecretDetails struct {
+	LocalAddress  string     `json:"local_address,omitempty"`
+	CallerID      string     `json:"caller_id,omitempty"` // Caller-id (MAC) the secret is bound to
+	Service       string     `json:"service,omitempty"`
+	Comment       string     `json:"comment,omitempty"`         // Usually the billing customer ID
+	LastLoggedOut *time.Time `json:"last_logged_out,omitempty"` // RouterOS v7
+	LastCallerID  string     `json:"last_caller_id,omitempty"`  // RouterOS v7
 }
 
 // UserWithStatus represents a user with their connection status
 type UserWithStatus struct {
-	Username string `json:"username"`
-	Status   string `json:"status"` // "connected", "isolated", or "offline"
-	IP       string `json:"ip,omitempty"`
-	Uptime   string `json:"uptime,omitempty"`
-	Profile  string `json:"profile,omitempty"`
+	Username         string         `json:"username"`
+	Status           string         `json:"status"` // "connected", "isolated", "suspended", or "offline"
+	IP               string         `json:"ip,omitempty"`
+	Uptime           string         `json:"uptime,omitempty"`
+	Profile          string         `json:"profile,omitempty"`
+	IsolationList    string         `json:"isolation_list,omitempty"`
+	IsolationComment string         `json:"isolation_comment,omitempty"`
+	Suspension       *Suspension    `json:"suspension,omitempty"`
+	Secret           *SecretDetails `json:"secret,omitempty"`
 }
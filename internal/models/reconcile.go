@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Reconciliation modes
+const (
+	ReconcilePlan  = "plan"  // Compute the changes only
+	ReconcileApply = "apply" // Compute and apply them
+	ReconcileDrift = "drift" // Compute them and alert if the router drifted
+)
+
+// Secret change actions
+const (
+	SecretCreate  = "create"
+	SecretUpdate  = "update"
+	SecretDisable = "disable"
+	SecretRemove  = "remove"
+)
+
+// DesiredSecret is how a PPP secret should look on its router
+type DesiredSecret struct {
+	Username      string `json:"username" binding:"required"`
+	Password      string `json:"password,omitempty"` // Needed to create missing secrets
+	Profile       string `json:"profile,omitempty"`
+	RemoteAddress string `json:"remote_address,omitempty"` // Empty leaves the router's value alone
+	Enabled       *bool  `json:"enabled,omitempty"`        // Empty leaves the router's value alone
+}
+
+// SecretChange is one difference between the desired state and a router
+type SecretChange struct {
+	Action   string            `json:"action"`
+	Username string            `json:"username"`
+	Fields   map[string]string `json:"fields,omitempty"` // RouterOS properties to set
+	Reason   string            `json:"reason,omitempty"`
+	Status   string            `json:"status,omitempty"` // apply mode: "applied", "failed" or "skipped"
+	Error    string            `json:"error,omitempty"`
+}
+
+// ReconcileResult is the outcome of reconciling one router
+type ReconcileResult struct {
+	RouterID   int            `json:"router_id"`
+	Mode       string         `json:"mode"`
+	Source     string         `json:"source"`
+	Desired    int            `json:"desired"`
+	Actual     int            `json:"actual"`
+	InSync     int            `json:"in_sync"`
+	Managed    int            `json:"managed"` // Suspended or FUP-limited users left alone
+	Counts     map[string]int `json:"counts"`
+	Changes    []SecretChange `json:"changes"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+}
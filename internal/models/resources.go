@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SystemResource struct {
 	Uptime       string `json:"uptime"`
 	CPU          string `json:"cpu"` // Load in %
@@ -14,3 +16,17 @@ type TrafficStats struct {
 	RX   int64  `json:"rx"`   // bps
 	TX   int64  `json:"tx"`   // bps
 }
+
+// ByteCounters are cumulative byte counters of a subscriber's queue
+type ByteCounters struct {
+	Name string `json:"name"` // Username
+	RX   int64  `json:"rx"`   // bytes, same direction as TrafficStats.RX
+	TX   int64  `json:"tx"`   // bytes
+}
+
+// UsagePoint is a subscriber's data usage in one period
+type UsagePoint struct {
+	Period time.Time `json:"period"` // Start of the hour, day or month
+	RX     int64     `json:"rx_bytes"`
+	TX     int64     `json:"tx_bytes"`
+}
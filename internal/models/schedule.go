@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Scheduled job states
+const (
+	SchedulePending   = "pending"   // Waiting for NextRunAt (recurring jobs stay pending)
+	ScheduleDone      = "done"      // One-shot job ran successfully
+	ScheduleFailed    = "failed"    // Rejected by the router or out of retries
+	ScheduleCancelled = "cancelled" // Cancelled through the API
+)
+
+// ScheduledJob is an Operation run once at RunAt or repeatedly on Cron
+type ScheduledJob struct {
+	ID int64 `json:"id"`
+	Operation
+	Cron      string     `json:"cron,omitempty"`
+	RunAt     *time.Time `json:"run_at,omitempty"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"` // Empty once the job is finished
+	Status    string     `json:"status"`
+	Attempts  int        `json:"attempts"` // Failed attempts of the current run
+	Runs      int        `json:"runs"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
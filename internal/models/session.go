@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Session is one PPP connection from the pppoe_sessions history
+type Session struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	RouterID  int        `json:"router_id"`
+	Address   string     `json:"address"`
+	CallerID  string     `json:"caller_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // nil while still connected
+	Duration  int64      `json:"duration"`           // Seconds; up to now for open sessions
+}
@@ -0,0 +1,19 @@
+package models
+
+// SubscriberRecord is everything known about a subscriber on one router
+type SubscriberRecord struct {
+	Username    string            `json:"username"`
+	RouterID    int               `json:"router_id"`
+	RouterName  string            `json:"router_name,omitempty"`
+	Session     string            `json:"session"` // "online" or "offline"
+	Status      string            `json:"status"`  // Same values as UserWithStatus.Status
+	Profile     string            `json:"profile,omitempty"`
+	IP          string            `json:"ip,omitempty"`
+	CallerID    string            `json:"caller_id,omitempty"`
+	Uptime      string            `json:"uptime,omitempty"`
+	Provisioned bool              `json:"provisioned"` // Has a pppoe_users row
+	Isolation   *AddressListEntry `json:"isolation,omitempty"`
+	Suspension  *Suspension       `json:"suspension,omitempty"`
+	Traffic     *TrafficStats     `json:"traffic,omitempty"`
+	Secret      *SecretDetails    `json:"secret,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Suspension strategies
+const (
+	SuspendDisable = "disable" // Disable the PPP secret and kick the session
+	SuspendIsolir  = "isolir"  // Move to a captive "isolir" profile and kick the session
+)
+
+// Suspension records what was changed on a secret so that resume can restore it exactly
+type Suspension struct {
+	Username         string    `json:"username"`
+	RouterID         int       `json:"router_id"`
+	Strategy         string    `json:"strategy"`
+	Profile          string    `json:"profile,omitempty"` // Profile applied while suspended (isolir strategy)
+	PreviousProfile  string    `json:"previous_profile"`
+	PreviousDisabled bool      `json:"previous_disabled"`
+	Comment          string    `json:"comment,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
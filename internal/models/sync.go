@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Sync delete policies for pppoe_users rows whose secret is gone from the router
+const (
+	SyncDeleteMark   = "mark"   // Set deleted_at (default)
+	SyncDeleteRemove = "delete" // Delete the row
+)
+
+// SyncSummary is the outcome of copying a router's secrets into pppoe_users
+type SyncSummary struct {
+	RouterID  int    `json:"router_id"`
+	Total     int    `json:"total"` // Secrets on the router
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Removed   int    `json:"removed"`
+	Policy    string `json:"delete_policy"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"` // Set once the transaction committed
+	DurationMs int64     `json:"duration_ms"`
+}